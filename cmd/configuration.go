@@ -6,6 +6,7 @@ import (
 	"github.com/darki73/goflaresync/pkg/log"
 	"github.com/spf13/cobra"
 	"os"
+	"strings"
 	"text/template"
 )
 
@@ -34,6 +35,30 @@ var configurationDisplayCmd = &cobra.Command{
 			config.Credentials.Email = "********"
 		}
 
+		if config.GetCredentials().GetKey() != "" {
+			config.Credentials.Key = "********"
+		}
+
+		for _, providerConfig := range config.Providers {
+			if credentials := providerConfig.GetCredentials(); credentials != nil {
+				if credentials.GetToken() != "" {
+					credentials.Token = "********"
+				}
+
+				if credentials.GetEmail() != "" {
+					credentials.Email = "********"
+				}
+
+				if credentials.GetKey() != "" {
+					credentials.Key = "********"
+				}
+			}
+
+			if rfc2136 := providerConfig.GetRFC2136(); rfc2136 != nil && rfc2136.GetTSIGSecret() != "" {
+				rfc2136.TSIGSecret = "********"
+			}
+		}
+
 		displayConfiguration(config)
 	},
 }
@@ -63,18 +88,65 @@ func init() {
 // displayConfiguration displays the configuration.
 func displayConfiguration(config *configuration.Configuration) {
 	tmplStr := `Credentials:
+  Auth Mode: {{ .Credentials.GetAuthMode }}
   Email: {{ .Credentials.Email }}
+{{- if .Credentials.Token }}
   Token: {{ .Credentials.Token }}
+{{- end }}
+{{- if .Credentials.Key }}
+  Key: {{ .Credentials.Key }}
+{{- end }}
+{{- if .Providers }}
+Providers:
+{{- range .Providers }}
+  - Name: {{ .GetName }}
+    Type: {{ .GetType }}
+{{- with .Credentials }}
+    Auth Mode: {{ .GetAuthMode }}
+    Email: {{ .Email }}
+{{- if .Token }}
+    Token: {{ .Token }}
+{{- end }}
+{{- if .Key }}
+    Key: {{ .Key }}
+{{- end }}
+{{- end }}
+{{- with .RFC2136 }}
+    Server: {{ .GetServer }}
+    Protocol: {{ .GetProtocol }}
+{{- if .TSIGKeyName }}
+    TSIG Key Name: {{ .TSIGKeyName }}
+    TSIG Secret: {{ .TSIGSecret }}
+    TSIG Algorithm: {{ .GetTSIGAlgorithm }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
 Records:
 {{- range .Records }}
   - Type: {{ .Type }}
     Name: {{ .Name }}
+    Provider: {{ .GetProvider }}
+{{- if .Proxied }}
+    Proxied: {{ .Proxied }}
+{{- end }}
+{{- if .TTL }}
+    TTL: {{ .TTL }}
+{{- end }}
+{{- if .Comment }}
+    Comment: {{ .Comment }}
+{{- end }}
+{{- if .Tags }}
+    Tags: {{ join .Tags ", " }}
+{{- end }}
 {{- end }}
 Watcher:
   Interval: {{ .Watcher.Interval }}
 Log Level: {{ .LogLevel }}
 `
-	tmpl, err := template.New("config").Parse(tmplStr)
+	tmpl, err := template.New("config").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(tmplStr)
 	if err != nil {
 		fmt.Println("Error parsing configuration template:", err)
 		return
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/watcher"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+)
+
+const (
+	// outputTable is the output format which prints the outcome as a table.
+	outputTable = "table"
+	// outputJSON is the output format which prints the outcome as JSON.
+	outputJSON = "json"
+)
+
+var (
+	// planRecordFilters is the list of filters which select the records to plan.
+	planRecordFilters []string
+	// planOutputFormat is the format the planned changes are printed in.
+	planOutputFormat string
+)
+
+// resultOutput is the definition of the outcome of a record printed in the JSON format.
+type resultOutput struct {
+	// Name is the name of the record.
+	Name string `json:"name"`
+	// Type is the type of the record.
+	Type string `json:"type"`
+	// Provider is the name of the provider instance which manages the record.
+	Provider string `json:"provider"`
+	// Address is the address the record was synchronized with.
+	Address string `json:"address,omitempty"`
+	// Status is the outcome of the synchronization.
+	Status watcher.Status `json:"status"`
+	// Error is the message of the error which caused the synchronization to fail.
+	Error string `json:"error,omitempty"`
+	// Changes is the list of changes made to the records, or which would be made.
+	Changes []*watcher.Change `json:"changes"`
+}
+
+// planCmd represents the plan command.
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Shows the changes the synchronization would make",
+	Long: `Resolves the current addresses, looks up the records and prints the changes the synchronization would make without making them.
+It exits with one of the following codes:
+  0 - all records are up to date
+  1 - any record could not be looked up or does not exist
+  2 - any record would be updated or created`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSynchronization(true, planRecordFilters, planOutputFormat)
+	},
+}
+
+// init initializes the plan command.
+func init() {
+	planCmd.Flags().StringArrayVar(&planRecordFilters, "record", nil, "Plan only the records matching the `name[/type]`, can be repeated")
+	planCmd.Flags().StringVarP(&planOutputFormat, "output", "o", outputTable, "Output `format`, either table or json")
+	rootCmd.AddCommand(planCmd)
+}
+
+// runSynchronization synchronizes the records matching the filters once, prints the outcome in the given format
+// and exits with the code which summarizes it. In the dry-run mode the changes are only printed without being made.
+func runSynchronization(dryRun bool, filters []string, outputFormat string) {
+	if outputFormat != outputTable && outputFormat != outputJSON {
+		log.Fatalf("unsupported output format `%s`, expected `%s` or `%s`", outputFormat, outputTable, outputJSON)
+	}
+
+	if err := initializeConfiguration(); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	monitoredRecords, err := filterRecords(filters)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	instance := watcher.New()
+	instance.SetDryRun(dryRun)
+
+	results, err := instance.Sync(ctx, monitoredRecords)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	switch {
+	case outputFormat == outputJSON:
+		err = printJSON(os.Stdout, results)
+	case dryRun:
+		printPlan(os.Stdout, results)
+	default:
+		printResults(os.Stdout, results)
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	stop()
+	os.Exit(getExitCode(results))
+}
+
+// printPlan prints the changes which would be made as a table, one row per changed attribute,
+// followed by the records which could not be looked up.
+func printPlan(output io.Writer, results []*watcher.Result) {
+	changes := 0
+	for _, result := range results {
+		changes += len(result.GetChanges())
+	}
+
+	if changes == 0 {
+		_, _ = fmt.Fprintln(output, "No changes.")
+	} else {
+		writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(writer, "ACTION\tPROVIDER\tZONE\tNAME\tTYPE\tATTRIBUTE\tOLD\tNEW")
+
+		for _, result := range results {
+			for _, change := range result.GetChanges() {
+				for _, attribute := range change.Attributes {
+					_, _ = fmt.Fprintf(
+						writer,
+						"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						change.Action,
+						change.Provider,
+						change.Zone,
+						change.Name,
+						change.Type,
+						attribute.Name,
+						attribute.Old,
+						attribute.New,
+					)
+				}
+			}
+		}
+
+		_ = writer.Flush()
+	}
+
+	for _, result := range results {
+		switch result.GetStatus() {
+		case watcher.StatusFailed, watcher.StatusSkipped:
+			_, _ = fmt.Fprintf(
+				output,
+				"record `%s` of type `%s` is %s: %v\n",
+				result.GetRecord().GetName(),
+				result.GetRecord().GetType(),
+				result.GetStatus(),
+				result.GetError(),
+			)
+		case watcher.StatusNotFound:
+			_, _ = fmt.Fprintf(
+				output,
+				"record `%s` of type `%s` does not exist and is not created\n",
+				result.GetRecord().GetName(),
+				result.GetRecord().GetType(),
+			)
+		}
+	}
+}
+
+// printJSON prints the outcome of every record along with its changes as JSON.
+func printJSON(output io.Writer, results []*watcher.Result) error {
+	outputs := make([]*resultOutput, 0, len(results))
+	for _, result := range results {
+		message := ""
+		if result.GetError() != nil {
+			message = result.GetError().Error()
+		}
+
+		changes := result.GetChanges()
+		if changes == nil {
+			changes = []*watcher.Change{}
+		}
+
+		outputs = append(outputs, &resultOutput{
+			Name:     result.GetRecord().GetName(),
+			Type:     result.GetRecord().GetType(),
+			Provider: result.GetRecord().GetProvider(),
+			Address:  result.GetAddress(),
+			Status:   result.GetStatus(),
+			Error:    message,
+			Changes:  changes,
+		})
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(outputs)
+}
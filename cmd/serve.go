@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	"github.com/darki73/goflaresync/pkg/dyndns"
+	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/provider/registry"
+	"github.com/darki73/goflaresync/pkg/watcher"
+	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+)
+
+// serveCmd represents the serve command.
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Starts the dyndns2 compatible update server",
+	Long: `Starts the dyndns2 compatible update server which applies the addresses pushed by clients such as routers to the monitored records.
+The following signals are handled:
+  SIGTERM, SIGINT - stop after the in-flight requests have finished, bounded by the shutdown timeout
+  SIGHUP          - reload the configuration`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initializeConfiguration(); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		if len(configuration.GetConfiguration().GetDynDNS().GetUsers()) == 0 {
+			log.Fatal("no users are allowed to push updates, configure at least one user in the `dyndns` section")
+		}
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, startSignals...)
+		defer signal.Stop(signals)
+
+		providers, err := registry.NewFromConfiguration(context.Background())
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		server := dyndns.NewServer(watcher.NewWithProviders(providers))
+		if err := server.Start(); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		for {
+			select {
+			case err := <-server.GetErrors():
+				log.Fatal(err.Error())
+			case <-configuration.ChangeChannel:
+				reloadProviders(server)
+			case received := <-signals:
+				switch received {
+				case reloadSignal:
+					if reloadConfiguration() {
+						reloadProviders(server)
+					}
+				case syncSignal:
+					log.InfofWithFields(
+						"received `%s`, the addresses are pushed by the clients so there is nothing to synchronize",
+						log.FieldsMap{
+							"source": "signal",
+						},
+						received.String(),
+					)
+				default:
+					shutdown(received, server.Stop)
+					return
+				}
+			}
+		}
+	},
+}
+
+// init initializes the serve command.
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+// reloadProviders initializes the providers from the current configuration and passes them to the server.
+// The server keeps the previous providers when they can not be initialized.
+func reloadProviders(server *dyndns.Server) {
+	providers, err := registry.NewFromConfiguration(context.Background())
+	if err != nil {
+		log.ErrorfWithFields(
+			"failed to re-initialize the providers, keeping the previous ones: %s",
+			log.FieldsMap{
+				"source": "dyndns",
+			},
+			err.Error(),
+		)
+		return
+	}
+
+	server.SetUpdater(watcher.NewWithProviders(providers))
+}
@@ -1,11 +1,73 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/address"
+	"github.com/darki73/goflaresync/pkg/configuration"
 	"github.com/darki73/goflaresync/pkg/log"
 	"github.com/darki73/goflaresync/pkg/service"
+	"github.com/darki73/goflaresync/pkg/state"
 	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 )
 
+const (
+	// serviceStatusOK is the exit code used when the service is running and the last synchronization succeeded.
+	serviceStatusOK = 0
+	// serviceStatusWarning is the exit code used when the last synchronization failed or is overdue.
+	serviceStatusWarning = 1
+	// serviceStatusCritical is the exit code used when the service is not installed or not running.
+	serviceStatusCritical = 2
+	// serviceStatusUnknown is the exit code used when the status of the service could not be determined.
+	serviceStatusUnknown = 3
+)
+
+// serviceStatusOutputFormat is the format the status of the service is printed in.
+var serviceStatusOutputFormat string
+
+// serviceStatusNames is the name of every exit code of the service status command.
+var serviceStatusNames = map[int]string{
+	serviceStatusOK:       "ok",
+	serviceStatusWarning:  "warning",
+	serviceStatusCritical: "critical",
+	serviceStatusUnknown:  "unknown",
+}
+
+// serviceStatusOutput is the definition of the status of the service printed by the service status command.
+type serviceStatusOutput struct {
+	// InitSystem is the detected initialization system.
+	InitSystem service.InitSystem `json:"init_system"`
+	// ServicePath is the path to the unit file or the init script.
+	ServicePath string `json:"service_path"`
+	// Installed is a flag that indicates if the service is installed.
+	Installed bool `json:"installed"`
+	// Enabled is a flag that indicates if the service is enabled to start on boot.
+	Enabled bool `json:"enabled"`
+	// Running is a flag that indicates if the service is running.
+	Running bool `json:"running"`
+	// ProcessIdentifier is the process identifier of the running application, 0 if it is not running.
+	ProcessIdentifier int `json:"pid"`
+	// LastSync is the time of the last successful synchronization, nil if there was none.
+	LastSync *time.Time `json:"last_sync"`
+	// LastAttempt is the time of the last synchronization, nil if there was none.
+	LastAttempt *time.Time `json:"last_attempt"`
+	// LastError is the error of the last synchronization, empty if it was successful.
+	LastError string `json:"last_error,omitempty"`
+	// Addresses is the last published address for every network.
+	Addresses map[string]string `json:"addresses"`
+	// Status is the overall status, one of `ok`, `warning`, `critical` and `unknown`.
+	Status string `json:"status"`
+	// Message is the explanation of the overall status.
+	Message string `json:"message"`
+	// exitCode is the exit code which corresponds to the overall status.
+	exitCode int
+}
+
 // serviceCmd represents the service command.
 var serviceCmd = &cobra.Command{
 	Use:   "service",
@@ -193,6 +255,38 @@ var serviceDisableCmd = &cobra.Command{
 	},
 }
 
+// serviceStatusCmd represents the service status command.
+var serviceStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the status of the service",
+	Long: `Shows the status of the service along with the outcome of the last synchronization and exits with one of the following codes:
+  0 - the service is running and the last synchronization succeeded
+  1 - the last synchronization failed or is overdue
+  2 - the service is not installed or not running
+  3 - the status could not be determined`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if serviceStatusOutputFormat != outputTable && serviceStatusOutputFormat != outputJSON {
+			log.Fatalf("unsupported output format `%s`, expected `%s` or `%s`", serviceStatusOutputFormat, outputTable, outputJSON)
+		}
+
+		status := getServiceStatus()
+
+		var err error
+		if serviceStatusOutputFormat == outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(status)
+		} else {
+			printServiceStatus(os.Stdout, status)
+		}
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		os.Exit(status.exitCode)
+	},
+}
+
 // init registers the command and flags.
 func init() {
 	serviceCmd.AddCommand(serviceInstallCmd)
@@ -202,5 +296,139 @@ func init() {
 	serviceCmd.AddCommand(serviceRestartCmd)
 	serviceCmd.AddCommand(serviceEnableCmd)
 	serviceCmd.AddCommand(serviceDisableCmd)
+	serviceStatusCmd.Flags().StringVarP(&serviceStatusOutputFormat, "output", "o", outputTable, "Output `format`, either table or json")
+	serviceCmd.AddCommand(serviceStatusCmd)
 	rootCmd.AddCommand(serviceCmd)
 }
+
+// getServiceStatus returns the status of the service along with the outcome of the last synchronization.
+func getServiceStatus() *serviceStatusOutput {
+	output := &serviceStatusOutput{
+		Addresses: map[string]string{},
+	}
+
+	manager := service.NewManager()
+	status, err := manager.GetStatus()
+	output.InitSystem = status.InitSystem
+	output.ServicePath = status.ServicePath
+	output.Installed = status.Installed
+	output.Enabled = status.Enabled
+	output.Running = status.Running
+	output.ProcessIdentifier = status.ProcessIdentifier
+
+	switch {
+	case !manager.HasSystemManager():
+		return output.setStatus(serviceStatusUnknown, fmt.Sprintf("initialization system `%s` is not supported", status.InitSystem))
+	case err != nil:
+		return output.setStatus(serviceStatusUnknown, fmt.Sprintf("failed to query the service: %s", err.Error()))
+	case !status.Installed:
+		return output.setStatus(serviceStatusCritical, "service is not installed")
+	}
+
+	if err := initializeConfiguration(); err != nil {
+		return output.setStatus(serviceStatusUnknown, fmt.Sprintf("failed to load the configuration: %s", err.Error()))
+	}
+	config := configuration.GetConfiguration().GetWatcher()
+
+	store := state.NewStore(config.GetStateFile())
+	if err := store.Load(); err != nil {
+		return output.setStatus(serviceStatusUnknown, fmt.Sprintf("failed to load the state from `%s`: %s", store.GetPath(), err.Error()))
+	}
+
+	if lastSync := store.GetLastSync(); !lastSync.IsZero() {
+		output.LastSync = &lastSync
+	}
+	if lastAttempt := store.GetLastAttempt(); !lastAttempt.IsZero() {
+		output.LastAttempt = &lastAttempt
+	}
+	output.LastError = store.GetLastError()
+	for _, network := range []string{address.NetworkIPv4, address.NetworkIPv6} {
+		if publishedAddress := store.GetAddress(network); publishedAddress != "" {
+			output.Addresses[network] = publishedAddress
+		}
+	}
+
+	return output.setSynchronizationStatus(config.GetInterval(), time.Now())
+}
+
+// setSynchronizationStatus sets the overall status of the installed service from its outcome of the last synchronization.
+func (output *serviceStatusOutput) setSynchronizationStatus(interval time.Duration, now time.Time) *serviceStatusOutput {
+	// A synchronization is considered overdue once several checks were missed.
+	overdue := 3 * interval
+
+	switch {
+	case !output.Running:
+		return output.setStatus(serviceStatusCritical, "service is not running")
+	case output.LastError != "":
+		return output.setStatus(serviceStatusWarning, fmt.Sprintf("last synchronization failed: %s", output.LastError))
+	case output.LastSync == nil:
+		return output.setStatus(serviceStatusWarning, "no successful synchronization yet")
+	case now.Sub(*output.LastSync) > overdue:
+		return output.setStatus(serviceStatusWarning, fmt.Sprintf("no successful synchronization for more than %s", overdue.String()))
+	}
+
+	return output.setStatus(serviceStatusOK, "service is running and the last synchronization succeeded")
+}
+
+// setStatus sets the overall status along with its explanation.
+func (output *serviceStatusOutput) setStatus(exitCode int, message string) *serviceStatusOutput {
+	output.exitCode = exitCode
+	output.Status = serviceStatusNames[exitCode]
+	output.Message = message
+	return output
+}
+
+// printServiceStatus prints the status of the service in the human-readable format.
+func printServiceStatus(output io.Writer, status *serviceStatusOutput) {
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+
+	addresses := make([]string, 0, len(status.Addresses))
+	for _, network := range []string{address.NetworkIPv4, address.NetworkIPv6} {
+		if publishedAddress, ok := status.Addresses[network]; ok {
+			addresses = append(addresses, publishedAddress)
+		}
+	}
+
+	processIdentifier := "-"
+	if status.ProcessIdentifier != 0 {
+		processIdentifier = fmt.Sprintf("%d", status.ProcessIdentifier)
+	}
+
+	_, _ = fmt.Fprintf(writer, "Init system:\t%s\n", status.InitSystem)
+	_, _ = fmt.Fprintf(writer, "Service path:\t%s\n", formatOptional(status.ServicePath))
+	_, _ = fmt.Fprintf(writer, "Installed:\t%s\n", formatFlag(status.Installed))
+	_, _ = fmt.Fprintf(writer, "Enabled:\t%s\n", formatFlag(status.Enabled))
+	_, _ = fmt.Fprintf(writer, "Running:\t%s\n", formatFlag(status.Running))
+	_, _ = fmt.Fprintf(writer, "PID:\t%s\n", processIdentifier)
+	_, _ = fmt.Fprintf(writer, "Last sync:\t%s\n", formatTime(status.LastSync))
+	_, _ = fmt.Fprintf(writer, "Last attempt:\t%s\n", formatTime(status.LastAttempt))
+	_, _ = fmt.Fprintf(writer, "Last error:\t%s\n", formatOptional(status.LastError))
+	_, _ = fmt.Fprintf(writer, "Addresses:\t%s\n", formatOptional(strings.Join(addresses, ", ")))
+	_, _ = fmt.Fprintf(writer, "Status:\t%s - %s\n", strings.ToUpper(status.Status), status.Message)
+
+	_ = writer.Flush()
+}
+
+// formatFlag returns `yes` or `no` for the flag.
+func formatFlag(flag bool) string {
+	if flag {
+		return "yes"
+	}
+	return "no"
+}
+
+// formatOptional returns the value, or a dash if it is empty.
+func formatOptional(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// formatTime returns the time along with how long ago it was, or a dash if there is none.
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s ago)", value.Local().Format(time.RFC3339), time.Since(*value).Round(time.Second).String())
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetSynchronizationStatus(t *testing.T) {
+	now := time.Date(2023, 7, 13, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-10 * time.Minute)
+	overdue := now.Add(-16 * time.Minute)
+
+	tests := []struct {
+		name      string
+		running   bool
+		lastSync  *time.Time
+		lastError string
+		expected  int
+	}{
+		{name: "the last synchronization succeeded", running: true, lastSync: &recent, expected: serviceStatusOK},
+		{name: "the last synchronization failed", running: true, lastSync: &recent, lastError: "timeout", expected: serviceStatusWarning},
+		{name: "there was no synchronization yet", running: true, expected: serviceStatusWarning},
+		{name: "the last synchronization is overdue", running: true, lastSync: &overdue, expected: serviceStatusWarning},
+		{name: "the service is not running", running: false, lastSync: &recent, expected: serviceStatusCritical},
+	}
+
+	for _, test := range tests {
+		output := &serviceStatusOutput{
+			Running:   test.running,
+			LastSync:  test.lastSync,
+			LastError: test.lastError,
+		}
+
+		output.setSynchronizationStatus(5*time.Minute, now)
+
+		if output.exitCode != test.expected {
+			t.Errorf("Expected %d when %s but got %d", test.expected, test.name, output.exitCode)
+		}
+		if output.Status != serviceStatusNames[test.expected] {
+			t.Errorf("Expected '%s' when %s but got '%s'", serviceStatusNames[test.expected], test.name, output.Status)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	// startSignals is the list of signals handled by the start command.
+	startSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1}
+	// reloadSignal is the signal which forces a reload of the configuration.
+	reloadSignal os.Signal = syscall.SIGHUP
+	// syncSignal is the signal which triggers an immediate synchronization.
+	syncSignal os.Signal = syscall.SIGUSR1
+)
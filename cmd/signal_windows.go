@@ -0,0 +1,17 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	// startSignals is the list of signals handled by the start command.
+	startSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	// reloadSignal is the signal which forces a reload of the configuration, it is never delivered on Windows.
+	reloadSignal os.Signal
+	// syncSignal is the signal which triggers an immediate synchronization, it is never delivered on Windows.
+	syncSignal os.Signal
+)
@@ -1,23 +1,39 @@
 package cmd
 
 import (
+	"context"
 	"github.com/darki73/goflaresync/pkg/configuration"
 	"github.com/darki73/goflaresync/pkg/log"
 	"github.com/darki73/goflaresync/pkg/service"
 	"github.com/darki73/goflaresync/pkg/watcher"
 	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+)
+
+var (
+	// startDryRun is a flag that indicates if the changes are only logged without being made.
+	startDryRun bool
 )
 
 // startCmd represents the start command
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Starts the application",
-	Long:  "Starts the application and begins the synchronization process",
+	Long: `Starts the application and begins the synchronization process.
+The following signals are handled:
+  SIGTERM, SIGINT - stop after the in-flight update has finished, bounded by the shutdown timeout
+  SIGHUP          - reload the configuration
+  SIGUSR1         - synchronize the records immediately`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := initializeConfiguration(); err != nil {
 			log.Fatal(err.Error())
 		}
 
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, startSignals...)
+		defer signal.Stop(signals)
+
 		manager := service.NewManager()
 		systemManager := manager.GetSystemManager()
 		if systemManager != nil {
@@ -34,22 +50,111 @@ var startCmd = &cobra.Command{
 		}
 
 		instance := watcher.New()
+		instance.SetDryRun(startDryRun)
 		if err := instance.Start(); err != nil {
 			log.Fatal(err.Error())
 		}
 
-		for {
-			select {
-			case <-configuration.ChangeChannel:
-				if err := instance.Restart(); err != nil {
-					log.Fatal(err.Error())
-				}
-			}
-		}
+		runWatcher(instance, signals)
 	},
 }
 
 // init initializes the start command.
 func init() {
+	startCmd.Flags().BoolVar(&startDryRun, "dry-run", false, "Log the changes which would be made without making them")
 	rootCmd.AddCommand(startCmd)
 }
+
+// runWatcher restarts the watcher when the configuration changes and handles the signals until a stop signal is received.
+// The watcher picks up the reloaded configuration, including the interval and the state file, when it is restarted.
+func runWatcher(instance *watcher.Watcher, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-configuration.ChangeChannel:
+			restartWatcher(instance)
+		case received := <-signals:
+			switch received {
+			case reloadSignal:
+				if reloadConfiguration() {
+					restartWatcher(instance)
+				}
+			case syncSignal:
+				log.InfoWithFields(
+					"synchronization requested",
+					log.FieldsMap{
+						"source": "signal",
+					},
+				)
+				instance.TriggerSync()
+			default:
+				shutdown(received, instance.Shutdown)
+				return
+			}
+		}
+	}
+}
+
+// restartWatcher restarts the watcher with the reloaded configuration.
+// The watcher keeps running with the previous configuration when it can not be restarted.
+func restartWatcher(instance *watcher.Watcher) {
+	if err := instance.Restart(); err != nil {
+		log.ErrorfWithFields(
+			"failed to apply the reloaded configuration, keeping the previous one: %s",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+			err.Error(),
+		)
+	}
+}
+
+// reloadConfiguration reads the configuration file again and returns a flag that indicates if it was reloaded.
+// The current configuration is kept when the file can not be read.
+func reloadConfiguration() bool {
+	log.InfoWithFields(
+		"configuration reload requested",
+		log.FieldsMap{
+			"source": "signal",
+		},
+	)
+
+	if err := configuration.ReloadConfiguration(); err != nil {
+		log.ErrorfWithFields(
+			"failed to reload configuration, keeping the current one: %s",
+			log.FieldsMap{
+				"source": "signal",
+			},
+			err.Error(),
+		)
+		return false
+	}
+
+	return true
+}
+
+// shutdown stops the running component with the given function, the in-flight work is aborted once the shutdown timeout has passed.
+func shutdown(received os.Signal, stop func(ctx context.Context) error) {
+	timeout := configuration.GetConfiguration().GetWatcher().GetShutdownTimeout()
+
+	log.InfofWithFields(
+		"received `%s`, shutting down",
+		log.FieldsMap{
+			"source": "signal",
+		},
+		received.String(),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := stop(ctx); err != nil {
+		log.WarnfWithFields(
+			"shutdown did not finish within %s: %s",
+			log.FieldsMap{
+				"source": "signal",
+			},
+			timeout.String(),
+			err.Error(),
+		)
+	}
+}
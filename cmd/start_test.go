@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"github.com/darki73/goflaresync/pkg/configuration"
+	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/watcher"
+	"github.com/spf13/viper"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func init() {
+	log.SetOutput(io.Discard)
+}
+
+func writeTestConfiguration(t *testing.T, path string, interval string, records string) {
+	content := "watcher:\n" +
+		"  interval: " + interval + "\n" +
+		"  events: false\n" +
+		"  state_file: " + filepath.Join(filepath.Dir(path), "state.json") + "\n" +
+		"records: " + records + "\n"
+
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write the configuration: %v", err)
+	}
+}
+
+// loadTestConfiguration loads the configuration file without watching it, so only the signals trigger a restart.
+func loadTestConfiguration(t *testing.T, path string) {
+	viper.SetConfigFile(path)
+
+	if err := configuration.ReloadConfiguration(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+}
+
+func TestReloadSignalAppliesWatcherConfiguration(t *testing.T) {
+	if reloadSignal == nil {
+		t.Skip("the reload signal is not supported on this platform")
+	}
+
+	directory := t.TempDir()
+	path := filepath.Join(directory, "config.yaml")
+	writeTestConfiguration(t, path, "5m", "[]")
+
+	loadTestConfiguration(t, path)
+
+	instance := watcher.New()
+	if err := instance.Start(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		runWatcher(instance, signals)
+	}()
+
+	writeTestConfiguration(t, path, "10m", "[]")
+	signals <- reloadSignal
+	signals <- os.Interrupt
+
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the watcher to stop")
+	}
+
+	if instance.GetInterval() != 10*time.Minute {
+		t.Errorf("Expected the interval to be %s after the reload but got %s", 10*time.Minute, instance.GetInterval())
+	}
+}
+
+func TestReloadSignalKeepsWatcherWhenReloadedConfigurationDoesNotStart(t *testing.T) {
+	if reloadSignal == nil {
+		t.Skip("the reload signal is not supported on this platform")
+	}
+
+	directory := t.TempDir()
+	path := filepath.Join(directory, "config.yaml")
+	writeTestConfiguration(t, path, "5m", "[]")
+
+	loadTestConfiguration(t, path)
+
+	instance := watcher.New()
+	if err := instance.Start(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	signals := make(chan os.Signal)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		runWatcher(instance, signals)
+	}()
+
+	writeTestConfiguration(t, path, "10m", "[{type: A, name: home.example.com, provider: missing}]")
+	signals <- reloadSignal
+	signals <- syncSignal
+
+	if instance.GetInterval() != 5*time.Minute {
+		t.Errorf("Expected the previous interval %s to be kept but got %s", 5*time.Minute, instance.GetInterval())
+	}
+
+	writeTestConfiguration(t, path, "10m", "[]")
+	signals <- reloadSignal
+	signals <- os.Interrupt
+
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the watcher to stop")
+	}
+
+	if instance.GetInterval() != 10*time.Minute {
+		t.Errorf("Expected the interval to be %s after the valid reload but got %s", 10*time.Minute, instance.GetInterval())
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	"github.com/darki73/goflaresync/pkg/configuration/records"
+	"github.com/darki73/goflaresync/pkg/watcher"
+	"github.com/spf13/cobra"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// exitCodeUnchanged is the exit code used when all records were already up to date.
+	exitCodeUnchanged = 0
+	// exitCodeError is the exit code used when any record could not be synchronized or does not exist.
+	exitCodeError = 1
+	// exitCodeChanged is the exit code used when any record was updated or created.
+	exitCodeChanged = 2
+)
+
+var (
+	// syncRecordFilters is the list of filters which select the records to synchronize.
+	syncRecordFilters []string
+	// syncDryRun is a flag that indicates if the changes are only printed without being made.
+	syncDryRun bool
+	// syncOutputFormat is the format the outcome of the synchronization is printed in.
+	syncOutputFormat string
+)
+
+// syncCmd represents the sync command.
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Synchronizes the records once",
+	Long: `Synchronizes the records once, prints the outcome of every record and exits with one of the following codes:
+  0 - all records were already up to date
+  1 - any record could not be synchronized or does not exist
+  2 - any record was updated or created`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSynchronization(syncDryRun, syncRecordFilters, syncOutputFormat)
+	},
+}
+
+// init initializes the sync command.
+func init() {
+	syncCmd.Flags().StringArrayVar(&syncRecordFilters, "record", nil, "Synchronize only the records matching the `name[/type]`, can be repeated")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Print the changes which would be made without making them, same as the plan command")
+	syncCmd.Flags().StringVarP(&syncOutputFormat, "output", "o", outputTable, "Output `format`, either table or json")
+	rootCmd.AddCommand(syncCmd)
+}
+
+// filterRecords returns the configured records which match any of the filters, or nil if there are no filters.
+// A filter is the name of the record, optionally followed by a slash and the type of the record.
+func filterRecords(filters []string) ([]*records.Configuration, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	monitoredRecords := []*records.Configuration{}
+	for _, monitoredRecord := range configuration.GetConfiguration().GetRecords() {
+		for _, filter := range filters {
+			if isMatchingRecord(monitoredRecord, filter) {
+				monitoredRecords = append(monitoredRecords, monitoredRecord)
+				break
+			}
+		}
+	}
+
+	if len(monitoredRecords) == 0 {
+		return nil, fmt.Errorf("no configured record matches `%s`", strings.Join(filters, "`, `"))
+	}
+
+	return monitoredRecords, nil
+}
+
+// isMatchingRecord checks if the monitored record matches the filter.
+func isMatchingRecord(monitoredRecord *records.Configuration, filter string) bool {
+	name, recordType, hasType := strings.Cut(filter, "/")
+
+	if !strings.EqualFold(strings.TrimSuffix(name, "."), strings.TrimSuffix(monitoredRecord.GetName(), ".")) {
+		return false
+	}
+
+	return !hasType || strings.EqualFold(recordType, monitoredRecord.GetType())
+}
+
+// printResults prints the outcome of every record as a table.
+func printResults(output io.Writer, results []*watcher.Result) {
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "NAME\tTYPE\tPROVIDER\tADDRESS\tSTATUS\tERROR")
+
+	for _, result := range results {
+		message := ""
+		if result.GetError() != nil {
+			message = result.GetError().Error()
+		}
+
+		_, _ = fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\t%s\n",
+			result.GetRecord().GetName(),
+			result.GetRecord().GetType(),
+			result.GetRecord().GetProvider(),
+			result.GetAddress(),
+			result.GetStatus(),
+			message,
+		)
+	}
+
+	_ = writer.Flush()
+}
+
+// getExitCode returns the exit code which summarizes the outcome of the records.
+// A record which does not exist is an error, since it usually means the name of the record is misspelled.
+func getExitCode(results []*watcher.Result) int {
+	exitCode := exitCodeUnchanged
+
+	for _, result := range results {
+		switch result.GetStatus() {
+		case watcher.StatusFailed, watcher.StatusSkipped, watcher.StatusNotFound:
+			return exitCodeError
+		}
+
+		if result.IsChanged() {
+			exitCode = exitCodeChanged
+		}
+	}
+
+	return exitCode
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/darki73/goflaresync/pkg/watcher"
+	"testing"
+)
+
+func TestGetExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []watcher.Status
+		expected int
+	}{
+		{name: "all records are up to date", statuses: []watcher.Status{watcher.StatusUnchanged, watcher.StatusUnchanged}, expected: exitCodeUnchanged},
+		{name: "a record was updated", statuses: []watcher.Status{watcher.StatusUnchanged, watcher.StatusUpdated}, expected: exitCodeChanged},
+		{name: "a record was created", statuses: []watcher.Status{watcher.StatusCreated}, expected: exitCodeChanged},
+		{name: "a record does not exist", statuses: []watcher.Status{watcher.StatusUpdated, watcher.StatusNotFound}, expected: exitCodeError},
+		{name: "a record was skipped", statuses: []watcher.Status{watcher.StatusSkipped, watcher.StatusUpdated}, expected: exitCodeError},
+		{name: "a record failed", statuses: []watcher.Status{watcher.StatusUnchanged, watcher.StatusFailed}, expected: exitCodeError},
+	}
+
+	for _, test := range tests {
+		results := make([]*watcher.Result, 0, len(test.statuses))
+		for _, status := range test.statuses {
+			results = append(results, &watcher.Result{Status: status})
+		}
+
+		if exitCode := getExitCode(results); exitCode != test.expected {
+			t.Errorf("Expected %d when %s but got %d", test.expected, test.name, exitCode)
+		}
+	}
+}
+
+func TestCommandsDoNotShareFlags(t *testing.T) {
+	if err := syncCmd.Flags().Set("output", outputJSON); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	defer func() {
+		_ = syncCmd.Flags().Set("output", outputTable)
+	}()
+
+	if err := syncCmd.Flags().Set("record", "home.example.com"); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	defer func() {
+		syncRecordFilters = nil
+	}()
+
+	if planOutputFormat != outputTable || serviceStatusOutputFormat != outputTable {
+		t.Errorf("Expected the other commands to keep the '%s' output but got '%s' and '%s'", outputTable, planOutputFormat, serviceStatusOutputFormat)
+	}
+
+	if len(planRecordFilters) != 0 {
+		t.Errorf("Expected the plan command to have no record filters but got %v", planRecordFilters)
+	}
+}
@@ -0,0 +1,98 @@
+package address
+
+import (
+	"context"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	sourceConfiguration "github.com/darki73/goflaresync/pkg/configuration/address"
+	"github.com/darki73/goflaresync/pkg/transport"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const (
+	// NetworkIPv4 is the network used to discover the IPv4 address.
+	NetworkIPv4 = "tcp4"
+	// NetworkIPv6 is the network used to discover the IPv6 address.
+	NetworkIPv6 = "tcp6"
+)
+
+// GetNetworkForRecordType returns the network matching the address family of the given record type.
+func GetNetworkForRecordType(recordType string) (string, error) {
+	switch strings.ToUpper(recordType) {
+	case "A":
+		return NetworkIPv4, nil
+	case "AAAA":
+		return NetworkIPv6, nil
+	default:
+		return "", fmt.Errorf("unsupported record type: %s", recordType)
+	}
+}
+
+// GetExternalAddress returns the current public IP address for the given network.
+func GetExternalAddress(network string) (netip.Addr, error) {
+	return GetExternalAddressWithContext(context.Background(), network)
+}
+
+// GetExternalAddressWithContext returns the current public IP address for the given network.
+// Querying the address sources is aborted when the context is done.
+func GetExternalAddressWithContext(ctx context.Context, network string) (netip.Addr, error) {
+	resolver, err := NewResolverFromConfiguration(network)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	return resolver.ResolveWithContext(ctx)
+}
+
+// NewResolverFromConfiguration returns a new resolver for the given network based on the watcher configuration.
+func NewResolverFromConfiguration(network string) (*Resolver, error) {
+	config := configuration.GetConfiguration().GetWatcher()
+
+	var sources []*sourceConfiguration.Configuration
+
+	switch network {
+	case NetworkIPv4:
+		sources = config.GetAddressSources()
+	case NetworkIPv6:
+		sources = config.GetAddressSourcesIPv6()
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedNetwork, network)
+	}
+
+	strategy, err := ParseStrategy(config.GetAddressStrategy())
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := NewResolver(strategy, config.GetAddressQuorum())
+
+	baseTransport, err := transport.NewFromConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, source := range sources {
+		addressSource, err := NewSourceFromConfiguration(source, network, baseTransport)
+		if err != nil {
+			return nil, err
+		}
+		resolver.AddSource(addressSource)
+	}
+
+	return resolver, nil
+}
+
+// NewSourceFromConfiguration returns a new address source for the given network based on the source configuration.
+// HTTP sources send the requests using a copy of the given transport.
+func NewSourceFromConfiguration(source *sourceConfiguration.Configuration, network string, baseTransport *http.Transport) (Source, error) {
+	switch source.GetType() {
+	case sourceConfiguration.TypeHTTP:
+		return NewHTTPSourceWithTransport(source.GetURL(), network, source.GetTimeout(), baseTransport), nil
+	case sourceConfiguration.TypeInterface:
+		return NewInterfaceSource(source.GetInterface(), network, source.GetCIDR())
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSourceType, source.GetType())
+	}
+}
@@ -0,0 +1,86 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	sourceConfiguration "github.com/darki73/goflaresync/pkg/configuration/address"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetNetworkForRecordType(t *testing.T) {
+	tests := []struct {
+		recordType string
+		expected   string
+		valid      bool
+	}{
+		{"A", NetworkIPv4, true},
+		{"a", NetworkIPv4, true},
+		{"AAAA", NetworkIPv6, true},
+		{"aaaa", NetworkIPv6, true},
+		{"CNAME", "", false},
+	}
+
+	for _, test := range tests {
+		network, err := GetNetworkForRecordType(test.recordType)
+		if test.valid && err != nil {
+			t.Errorf("Expected no error for '%s' but got %v", test.recordType, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("Expected an error for '%s' but got none", test.recordType)
+		}
+		if network != test.expected {
+			t.Errorf("Expected '%s' for '%s' but got '%s'", test.expected, test.recordType, network)
+		}
+	}
+}
+
+func TestNewResolverFromConfigurationUsesSourcesOfNetwork(t *testing.T) {
+	config := configuration.InitializeWithDefaults()
+	config.Watcher.AddressSources = []*sourceConfiguration.Configuration{sourceConfiguration.New("https://ipv4.example.com")}
+	config.Watcher.AddressSourcesIPv6 = []*sourceConfiguration.Configuration{sourceConfiguration.New("https://ipv6.example.com")}
+	configuration.SetConfiguration(config)
+
+	tests := []struct {
+		network  string
+		expected string
+	}{
+		{NetworkIPv4, "https://ipv4.example.com"},
+		{NetworkIPv6, "https://ipv6.example.com"},
+	}
+
+	for _, test := range tests {
+		resolver, err := NewResolverFromConfiguration(test.network)
+		if err != nil {
+			t.Fatalf("Expected no error for '%s' but got %v", test.network, err)
+		}
+
+		sources := resolver.GetSources()
+		if len(sources) != 1 || sources[0].GetName() != test.expected {
+			t.Errorf("Expected the source '%s' for '%s' but got %v", test.expected, test.network, sources)
+		}
+	}
+
+	if _, err := NewResolverFromConfiguration("udp"); !errors.Is(err, ErrUnsupportedNetwork) {
+		t.Errorf("Expected '%v' but got '%v'", ErrUnsupportedNetwork, err)
+	}
+}
+
+func TestNetworkTransportDialsOverGivenNetwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte("93.184.216.34"))
+	}))
+	defer server.Close()
+
+	if _, err := NewHTTPSource(server.URL, NetworkIPv4, 0).GetAddress(context.Background()); err != nil {
+		t.Errorf("Expected no error over '%s' but got %v", NetworkIPv4, err)
+	}
+
+	var dialError *net.OpError
+	if _, err := NewHTTPSource(server.URL, NetworkIPv6, 0).GetAddress(context.Background()); !errors.As(err, &dialError) {
+		t.Errorf("Expected the IPv4 server to be unreachable over '%s' but got %v", NetworkIPv6, err)
+	}
+}
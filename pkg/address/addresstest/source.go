@@ -0,0 +1,95 @@
+// Package addresstest provides a fake address source which reports the address set by the test.
+package addresstest
+
+import (
+	"context"
+	"github.com/darki73/goflaresync/pkg/address"
+	"net/netip"
+	"sync"
+)
+
+// Source is the fake address source.
+type Source struct {
+	// name is the name of the source.
+	name string
+	// mutex guards the state of the source.
+	mutex sync.Mutex
+	// address is the address reported by the source.
+	address netip.Addr
+	// err is the error reported by the source instead of the address.
+	err error
+	// calls is the number of times the address was requested.
+	calls int
+}
+
+// NewSource returns a new fake address source reporting the given address.
+func NewSource(name string, value string) *Source {
+	source := &Source{name: name}
+	source.SetAddress(value)
+	return source
+}
+
+// GetName returns the name of the source.
+func (source *Source) GetName() string {
+	return source.name
+}
+
+// GetAddress returns the address set by the test, or the error if one is set.
+func (source *Source) GetAddress(ctx context.Context) (netip.Addr, error) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+
+	source.calls++
+
+	if err := ctx.Err(); err != nil {
+		return netip.Addr{}, err
+	}
+
+	if source.err != nil {
+		return netip.Addr{}, source.err
+	}
+
+	if !source.address.IsValid() {
+		return netip.Addr{}, address.ErrNoAddress
+	}
+
+	return source.address, nil
+}
+
+// SetAddress sets the address reported by the source and clears the error.
+// An empty value makes the source report no address.
+func (source *Source) SetAddress(value string) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+
+	source.address = netip.Addr{}
+	if value != "" {
+		source.address = netip.MustParseAddr(value)
+	}
+	source.err = nil
+}
+
+// SetError makes the source report the error instead of the address.
+func (source *Source) SetError(err error) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+
+	source.err = err
+}
+
+// GetCalls returns the number of times the address was requested.
+func (source *Source) GetCalls() int {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+
+	return source.calls
+}
+
+// NewResolver returns a resolver which uses the fake address sources in order.
+func NewResolver(sources ...*Source) *address.Resolver {
+	resolver := address.NewResolver(address.StrategyFirstSuccess, 0)
+	for _, source := range sources {
+		resolver.AddSource(source)
+	}
+	return resolver
+}
@@ -0,0 +1,41 @@
+package address
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIsBogon(t *testing.T) {
+	tests := []struct {
+		input  string
+		output bool
+	}{
+		{"10.1.2.3", true},
+		{"100.64.0.1", true},
+		{"100.127.255.254", true},
+		{"127.0.0.1", true},
+		{"169.254.1.1", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"0.0.0.0", true},
+		{"255.255.255.255", true},
+		{"::1", true},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"2001:db8::1", true},
+		{"100.128.0.1", false},
+		{"1.1.1.1", false},
+		{"172.32.0.1", false},
+		{"2606:4700:4700::1111", false},
+	}
+
+	for _, test := range tests {
+		if result := IsBogon(netip.MustParseAddr(test.input)); result != test.output {
+			t.Errorf("for input %s, expected %v but got %v", test.input, test.output, result)
+		}
+	}
+
+	if !IsBogon(netip.Addr{}) {
+		t.Errorf("Expected invalid address to be a bogon")
+	}
+}
@@ -0,0 +1,17 @@
+package address
+
+import "errors"
+
+var (
+	ErrNoSources          = errors.New("no address sources configured")
+	ErrNoAddress          = errors.New("none of the address sources returned an address")
+	ErrNoMajority         = errors.New("address sources did not reach the required agreement")
+	ErrUnknownStrategy    = errors.New("unknown address strategy")
+	ErrUnexpectedStatus   = errors.New("address source returned unexpected status code")
+	ErrInvalidAddress     = errors.New("address source returned invalid address")
+	ErrUnexpectedFamily   = errors.New("address source returned address of unexpected family")
+	ErrUnsupportedNetwork = errors.New("unsupported network")
+	ErrNoInterfaceAddress = errors.New("network interface has no suitable address")
+	ErrUnknownSourceType  = errors.New("unknown address source type")
+	ErrMonitorUnsupported = errors.New("address change monitoring is not supported on this platform")
+)
@@ -0,0 +1,122 @@
+package address
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+const (
+	// maximumResponseSize is the maximum number of bytes read from the address source response.
+	maximumResponseSize = 1024
+)
+
+// HTTPSource is the address source which retrieves the address from an HTTP endpoint.
+type HTTPSource struct {
+	// url is the URL of the endpoint.
+	url string
+	// network is the network the requests are forced over.
+	network string
+	// client is the HTTP client used to query the endpoint.
+	client *http.Client
+}
+
+// NewHTTPSource returns a new HTTP address source.
+func NewHTTPSource(url string, network string, timeout time.Duration) *HTTPSource {
+	return NewHTTPSourceWithTransport(url, network, timeout, http.DefaultTransport.(*http.Transport))
+}
+
+// NewHTTPSourceWithTransport returns a new HTTP address source which sends the requests using a copy of the given transport.
+func NewHTTPSourceWithTransport(url string, network string, timeout time.Duration, base *http.Transport) *HTTPSource {
+	return &HTTPSource{
+		url:     url,
+		network: network,
+		client: &http.Client{
+			Transport: newNetworkTransport(base, network),
+			Timeout:   timeout,
+		},
+	}
+}
+
+// GetName returns the name of the source.
+func (source *HTTPSource) GetName() string {
+	return source.url
+}
+
+// GetAddress returns the address reported by the source.
+func (source *HTTPSource) GetAddress(ctx context.Context) (netip.Addr, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, source.url, nil)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	response, err := source.client.Do(request)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return netip.Addr{}, fmt.Errorf("%w: %d", ErrUnexpectedStatus, response.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maximumResponseSize))
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	return ParseAddress(string(body), source.network)
+}
+
+// ParseAddress parses and normalises the address and ensures it belongs to the family of the given network.
+func ParseAddress(value string, network string) (netip.Addr, error) {
+	address, err := netip.ParseAddr(strings.TrimSpace(value))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("%w: %q", ErrInvalidAddress, truncate(value, 64))
+	}
+
+	address = address.Unmap().WithZone("")
+
+	switch network {
+	case NetworkIPv4:
+		if !address.Is4() {
+			return netip.Addr{}, fmt.Errorf("%w: expected IPv4, got %s", ErrUnexpectedFamily, address)
+		}
+	case NetworkIPv6:
+		if !address.Is6() {
+			return netip.Addr{}, fmt.Errorf("%w: expected IPv6, got %s", ErrUnexpectedFamily, address)
+		}
+	default:
+		return netip.Addr{}, fmt.Errorf("%w: %s", ErrUnsupportedNetwork, network)
+	}
+
+	return address, nil
+}
+
+// truncate truncates the value to the given length.
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	return value[:length] + "..."
+}
+
+// newNetworkTransport returns a copy of the transport that only dials over the given network.
+// When a proxy is used, the connection to the proxy is dialed over the given network.
+func newNetworkTransport(base *http.Transport, network string) *http.Transport {
+	transport := base.Clone()
+	dialer := &net.Dialer{}
+
+	transport.DialContext = func(ctx context.Context, _ string, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	return transport
+}
@@ -0,0 +1,79 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPSourceNormalisesAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = fmt.Fprint(writer, "  198.51.100.7\n")
+	}))
+	defer server.Close()
+
+	result, err := NewHTTPSource(server.URL, NetworkIPv4, time.Second).GetAddress(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if result.String() != "198.51.100.7" {
+		t.Errorf("Expected '198.51.100.7' but got '%s'", result)
+	}
+}
+
+func TestHTTPSourceRejectsNonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprint(writer, "198.51.100.7")
+	}))
+	defer server.Close()
+
+	if _, err := NewHTTPSource(server.URL, NetworkIPv4, time.Second).GetAddress(context.Background()); !errors.Is(err, ErrUnexpectedStatus) {
+		t.Errorf("Expected '%v' but got '%v'", ErrUnexpectedStatus, err)
+	}
+}
+
+func TestHTTPSourceRejectsGarbage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = fmt.Fprint(writer, "<html><body>Please log in</body></html>")
+	}))
+	defer server.Close()
+
+	if _, err := NewHTTPSource(server.URL, NetworkIPv4, time.Second).GetAddress(context.Background()); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("Expected '%v' but got '%v'", ErrInvalidAddress, err)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		input   string
+		network string
+		output  string
+		err     error
+	}{
+		{"203.0.114.1", NetworkIPv4, "203.0.114.1", nil},
+		{"\t203.0.114.1\r\n", NetworkIPv4, "203.0.114.1", nil},
+		{"::ffff:203.0.114.1", NetworkIPv4, "203.0.114.1", nil},
+		{"2606:4700::1", NetworkIPv6, "2606:4700::1", nil},
+		{"2606:4700::1", NetworkIPv4, "", ErrUnexpectedFamily},
+		{"203.0.114.1", NetworkIPv6, "", ErrUnexpectedFamily},
+		{"not an address", NetworkIPv4, "", ErrInvalidAddress},
+		{"", NetworkIPv4, "", ErrInvalidAddress},
+	}
+
+	for _, test := range tests {
+		result, err := ParseAddress(test.input, test.network)
+		if !errors.Is(err, test.err) {
+			t.Errorf("for input %q, expected error '%v' but got '%v'", test.input, test.err, err)
+			continue
+		}
+		if err == nil && result.String() != test.output {
+			t.Errorf("for input %q, expected '%s' but got '%s'", test.input, test.output, result)
+		}
+	}
+}
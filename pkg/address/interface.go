@@ -0,0 +1,122 @@
+package address
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// InterfaceSource is the address source which reads the address from a local network interface.
+type InterfaceSource struct {
+	// name is the name of the network interface.
+	name string
+	// network is the network which defines the address family.
+	network string
+	// prefix is the network the address has to belong to.
+	prefix netip.Prefix
+}
+
+// NewInterfaceSource returns a new network interface address source.
+// An empty CIDR means that addresses from any network are accepted.
+func NewInterfaceSource(name string, network string, cidr string) (*InterfaceSource, error) {
+	source := &InterfaceSource{
+		name:    name,
+		network: network,
+	}
+
+	if cidr != "" {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		source.prefix = prefix.Masked()
+	}
+
+	return source, nil
+}
+
+// GetName returns the name of the source.
+func (source *InterfaceSource) GetName() string {
+	return fmt.Sprintf("interface://%s", source.name)
+}
+
+// GetAddress returns the address of the network interface.
+// The address is read locally, so the context is only checked before reading it.
+func (source *InterfaceSource) GetAddress(ctx context.Context) (netip.Addr, error) {
+	if err := ctx.Err(); err != nil {
+		return netip.Addr{}, err
+	}
+
+	networkInterface, err := net.InterfaceByName(source.name)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	interfaceAddresses, err := networkInterface.Addrs()
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	var candidates []netip.Addr
+
+	for _, interfaceAddress := range interfaceAddresses {
+		ipNetwork, ok := interfaceAddress.(*net.IPNet)
+		if !ok {
+			continue
+		}
+
+		candidate, ok := netip.AddrFromSlice(ipNetwork.IP)
+		if !ok {
+			continue
+		}
+
+		candidates = append(candidates, candidate.Unmap())
+	}
+
+	return selectInterfaceAddress(candidates, source.network, source.prefix, unusableAddresses(source.name))
+}
+
+// selectInterfaceAddress picks the most suitable address out of the interface addresses.
+// Public addresses are preferred over other global unicast addresses, while loopback, link-local,
+// multicast and unusable (temporary or deprecated) addresses are never picked.
+func selectInterfaceAddress(candidates []netip.Addr, network string, prefix netip.Prefix, unusable map[netip.Addr]bool) (netip.Addr, error) {
+	var fallback netip.Addr
+
+	for _, candidate := range candidates {
+		switch network {
+		case NetworkIPv4:
+			if !candidate.Is4() {
+				continue
+			}
+		case NetworkIPv6:
+			if !candidate.Is6() {
+				continue
+			}
+		default:
+			return netip.Addr{}, fmt.Errorf("%w: %s", ErrUnsupportedNetwork, network)
+		}
+
+		if !candidate.IsGlobalUnicast() || unusable[candidate] {
+			continue
+		}
+
+		if prefix.IsValid() && !prefix.Contains(candidate) {
+			continue
+		}
+
+		if !IsBogon(candidate) {
+			return candidate, nil
+		}
+
+		if !fallback.IsValid() {
+			fallback = candidate
+		}
+	}
+
+	if fallback.IsValid() {
+		return fallback, nil
+	}
+
+	return netip.Addr{}, ErrNoInterfaceAddress
+}
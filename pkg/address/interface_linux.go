@@ -0,0 +1,54 @@
+//go:build linux
+
+package address
+
+import (
+	"bufio"
+	"encoding/hex"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// interfaceAddressFlagTemporary is the flag of temporary (privacy extension) IPv6 addresses.
+	interfaceAddressFlagTemporary = 0x01
+	// interfaceAddressFlagDeprecated is the flag of deprecated IPv6 addresses.
+	interfaceAddressFlagDeprecated = 0x20
+)
+
+// unusableAddresses returns temporary and deprecated IPv6 addresses of the network interface.
+func unusableAddresses(name string) map[netip.Addr]bool {
+	unusable := make(map[netip.Addr]bool)
+
+	file, err := os.Open("/proc/net/if_inet6")
+	if err != nil {
+		return unusable
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 6 || fields[5] != name {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[4], 16, 32)
+		if err != nil || flags&(interfaceAddressFlagTemporary|interfaceAddressFlagDeprecated) == 0 {
+			continue
+		}
+
+		raw, err := hex.DecodeString(fields[0])
+		if err != nil {
+			continue
+		}
+
+		if address, ok := netip.AddrFromSlice(raw); ok {
+			unusable[address] = true
+		}
+	}
+
+	return unusable
+}
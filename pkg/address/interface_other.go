@@ -0,0 +1,11 @@
+//go:build !linux
+
+package address
+
+import "net/netip"
+
+// unusableAddresses returns temporary and deprecated IPv6 addresses of the network interface.
+// Address flags are only available on Linux, so every address is considered usable elsewhere.
+func unusableAddresses(_ string) map[netip.Addr]bool {
+	return make(map[netip.Addr]bool)
+}
@@ -0,0 +1,78 @@
+package address
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func parseAddresses(values ...string) []netip.Addr {
+	var addresses []netip.Addr
+	for _, value := range values {
+		addresses = append(addresses, netip.MustParseAddr(value))
+	}
+	return addresses
+}
+
+func TestSelectInterfaceAddressPrefersPublic(t *testing.T) {
+	candidates := parseAddresses("127.0.0.1", "192.168.1.10", "203.0.114.10", "fe80::1")
+
+	result, err := selectInterfaceAddress(candidates, NetworkIPv4, netip.Prefix{}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if result.String() != "203.0.114.10" {
+		t.Errorf("Expected '203.0.114.10' but got '%s'", result)
+	}
+}
+
+func TestSelectInterfaceAddressFallsBackToPrivate(t *testing.T) {
+	candidates := parseAddresses("127.0.0.1", "100.64.1.10")
+
+	result, err := selectInterfaceAddress(candidates, NetworkIPv4, netip.Prefix{}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if result.String() != "100.64.1.10" {
+		t.Errorf("Expected '100.64.1.10' but got '%s'", result)
+	}
+}
+
+func TestSelectInterfaceAddressSkipsTemporary(t *testing.T) {
+	candidates := parseAddresses("fe80::1", "2606:4700::abcd:1234", "2606:4700::1")
+	unusable := map[netip.Addr]bool{
+		netip.MustParseAddr("2606:4700::abcd:1234"): true,
+	}
+
+	result, err := selectInterfaceAddress(candidates, NetworkIPv6, netip.Prefix{}, unusable)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if result.String() != "2606:4700::1" {
+		t.Errorf("Expected '2606:4700::1' but got '%s'", result)
+	}
+}
+
+func TestSelectInterfaceAddressMatchesCIDR(t *testing.T) {
+	candidates := parseAddresses("203.0.114.10", "198.51.101.20")
+
+	result, err := selectInterfaceAddress(candidates, NetworkIPv4, netip.MustParsePrefix("198.51.101.0/24"), nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if result.String() != "198.51.101.20" {
+		t.Errorf("Expected '198.51.101.20' but got '%s'", result)
+	}
+}
+
+func TestSelectInterfaceAddressWithoutMatch(t *testing.T) {
+	candidates := parseAddresses("127.0.0.1", "fe80::1")
+
+	if _, err := selectInterfaceAddress(candidates, NetworkIPv6, netip.Prefix{}, nil); !errors.Is(err, ErrNoInterfaceAddress) {
+		t.Errorf("Expected '%v' but got '%v'", ErrNoInterfaceAddress, err)
+	}
+}
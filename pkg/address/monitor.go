@@ -0,0 +1,30 @@
+package address
+
+// Monitor is the definition of the monitor which reports local address changes.
+type Monitor struct {
+	// events is the channel which receives a value every time a local address changes.
+	events chan struct{}
+	// closer releases the resources held by the monitor.
+	closer func() error
+}
+
+// GetEvents returns the channel which receives a value every time a local address changes.
+func (monitor *Monitor) GetEvents() <-chan struct{} {
+	return monitor.events
+}
+
+// Close stops the monitor.
+func (monitor *Monitor) Close() error {
+	if monitor.closer == nil {
+		return nil
+	}
+	return monitor.closer()
+}
+
+// notify reports an address change without blocking when a change is already pending.
+func (monitor *Monitor) notify() {
+	select {
+	case monitor.events <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,86 @@
+//go:build linux
+
+package address
+
+import (
+	"github.com/darki73/goflaresync/pkg/log"
+	"os"
+	"syscall"
+)
+
+const (
+	// netlinkGroupIPv4Address is the netlink multicast group of IPv4 address changes (RTMGRP_IPV4_IFADDR).
+	netlinkGroupIPv4Address = 0x10
+	// netlinkGroupIPv6Address is the netlink multicast group of IPv6 address changes (RTMGRP_IPV6_IFADDR).
+	netlinkGroupIPv6Address = 0x100
+)
+
+// NewMonitor returns a new monitor subscribed to the netlink RTM_NEWADDR and RTM_DELADDR events.
+func NewMonitor() (*Monitor, error) {
+	descriptor, err := syscall.Socket(
+		syscall.AF_NETLINK,
+		syscall.SOCK_RAW|syscall.SOCK_CLOEXEC|syscall.SOCK_NONBLOCK,
+		syscall.NETLINK_ROUTE,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Bind(descriptor, &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: netlinkGroupIPv4Address | netlinkGroupIPv6Address,
+	}); err != nil {
+		_ = syscall.Close(descriptor)
+		return nil, err
+	}
+
+	// The descriptor is non-blocking, so the file is registered with the runtime poller
+	// and closing it unblocks the pending read.
+	file := os.NewFile(uintptr(descriptor), "netlink")
+
+	monitor := &Monitor{
+		events: make(chan struct{}, 1),
+		closer: file.Close,
+	}
+
+	go monitor.receive(file)
+
+	return monitor, nil
+}
+
+// receive reads the netlink messages until the monitor is closed.
+func (monitor *Monitor) receive(file *os.File) {
+	buffer := make([]byte, os.Getpagesize()*16)
+
+	for {
+		length, err := file.Read(buffer)
+		if err != nil {
+			log.DebugfWithFields(
+				"address monitor stopped: %s",
+				log.FieldsMap{
+					"source": "address",
+				},
+				err.Error(),
+			)
+			return
+		}
+
+		messages, err := syscall.ParseNetlinkMessage(buffer[:length])
+		if err != nil {
+			continue
+		}
+
+		for _, message := range messages {
+			switch message.Header.Type {
+			case syscall.RTM_NEWADDR, syscall.RTM_DELADDR:
+				log.TraceWithFields(
+					"local address change detected",
+					log.FieldsMap{
+						"source": "address",
+					},
+				)
+				monitor.notify()
+			}
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package address
+
+// NewMonitor returns a new monitor.
+// Address change notifications are only available on Linux.
+func NewMonitor() (*Monitor, error) {
+	return nil, ErrMonitorUnsupported
+}
@@ -0,0 +1,229 @@
+package address
+
+import (
+	"context"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/log"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Strategy is the type of the strategy used to pick the address out of the sources.
+type Strategy string
+
+const (
+	// StrategyFirstSuccess uses the address of the first source which responds successfully.
+	StrategyFirstSuccess Strategy = "first-success"
+	// StrategyMajority uses the address reported by the majority of the sources.
+	StrategyMajority Strategy = "majority"
+)
+
+// ParseStrategy parses the given string and returns the corresponding strategy.
+func ParseStrategy(strategy string) (Strategy, error) {
+	switch strings.ToLower(strategy) {
+	case "", "first", "first-success", "first_success":
+		return StrategyFirstSuccess, nil
+	case "majority", "quorum":
+		return StrategyMajority, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownStrategy, strategy)
+	}
+}
+
+// Resolver is the definition of the resolver which combines multiple address sources.
+type Resolver struct {
+	// strategy is the strategy used to pick the address.
+	strategy Strategy
+	// quorum is the number of sources which have to agree when the majority strategy is used.
+	quorum int
+	// sources is the ordered list of address sources.
+	sources []Source
+}
+
+// sourceResult is the result returned by a single source.
+type sourceResult struct {
+	// source is the name of the source.
+	source string
+	// address is the address reported by the source.
+	address netip.Addr
+	// err is the error returned by the source.
+	err error
+}
+
+// NewResolver returns a new resolver.
+// A quorum lower than one means that more than half of the sources have to agree.
+func NewResolver(strategy Strategy, quorum int, sources ...Source) *Resolver {
+	return &Resolver{
+		strategy: strategy,
+		quorum:   quorum,
+		sources:  sources,
+	}
+}
+
+// AddSource appends the source to the list of sources.
+func (resolver *Resolver) AddSource(source Source) {
+	resolver.sources = append(resolver.sources, source)
+}
+
+// GetStrategy returns the strategy used to pick the address.
+func (resolver *Resolver) GetStrategy() Strategy {
+	return resolver.strategy
+}
+
+// GetQuorum returns the number of sources which have to agree when the majority strategy is used.
+func (resolver *Resolver) GetQuorum() int {
+	if resolver.quorum < 1 {
+		return len(resolver.sources)/2 + 1
+	}
+	return resolver.quorum
+}
+
+// GetSources returns the ordered list of address sources.
+func (resolver *Resolver) GetSources() []Source {
+	return resolver.sources
+}
+
+// Resolve returns the address picked according to the strategy.
+func (resolver *Resolver) Resolve() (netip.Addr, error) {
+	return resolver.ResolveWithContext(context.Background())
+}
+
+// ResolveWithContext returns the address picked according to the strategy.
+// Querying the sources is aborted when the context is done.
+func (resolver *Resolver) ResolveWithContext(ctx context.Context) (netip.Addr, error) {
+	if len(resolver.sources) == 0 {
+		return netip.Addr{}, ErrNoSources
+	}
+
+	switch resolver.strategy {
+	case StrategyMajority:
+		return resolver.resolveMajority(ctx)
+	default:
+		return resolver.resolveFirstSuccess(ctx)
+	}
+}
+
+// resolveFirstSuccess queries the sources in order and returns the first address.
+func (resolver *Resolver) resolveFirstSuccess(ctx context.Context) (netip.Addr, error) {
+	for _, source := range resolver.sources {
+		address, err := source.GetAddress(ctx)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return netip.Addr{}, ctxErr
+		}
+
+		if err != nil {
+			log.WarnfWithFields(
+				"address source `%s` failed, trying the next one: %s",
+				log.FieldsMap{
+					"source": "address",
+				},
+				source.GetName(),
+				err.Error(),
+			)
+			continue
+		}
+
+		return address, nil
+	}
+
+	return netip.Addr{}, ErrNoAddress
+}
+
+// resolveMajority queries all sources and returns the address reported by enough of them.
+func (resolver *Resolver) resolveMajority(ctx context.Context) (netip.Addr, error) {
+	results := resolver.queryAll(ctx)
+	if err := ctx.Err(); err != nil {
+		return netip.Addr{}, err
+	}
+	votes := make(map[netip.Addr]int)
+
+	for _, result := range results {
+		if result.err != nil {
+			log.WarnfWithFields(
+				"address source `%s` failed: %s",
+				log.FieldsMap{
+					"source": "address",
+				},
+				result.source,
+				result.err.Error(),
+			)
+			continue
+		}
+		votes[result.address]++
+	}
+
+	if len(votes) == 0 {
+		return netip.Addr{}, ErrNoAddress
+	}
+
+	if len(votes) > 1 {
+		resolver.logDisagreement(results)
+	}
+
+	winner, count := netip.Addr{}, 0
+	for address, votesCount := range votes {
+		if votesCount > count || (votesCount == count && address.Less(winner)) {
+			winner, count = address, votesCount
+		}
+	}
+
+	if count < resolver.GetQuorum() {
+		return netip.Addr{}, fmt.Errorf(
+			"%w: %d out of %d sources agreed on `%s`, %d required",
+			ErrNoMajority,
+			count,
+			len(resolver.sources),
+			winner,
+			resolver.GetQuorum(),
+		)
+	}
+
+	return winner, nil
+}
+
+// queryAll queries all sources concurrently and returns their results in order.
+func (resolver *Resolver) queryAll(ctx context.Context) []*sourceResult {
+	results := make([]*sourceResult, len(resolver.sources))
+	waitGroup := sync.WaitGroup{}
+
+	for index, source := range resolver.sources {
+		waitGroup.Add(1)
+		go func(index int, source Source) {
+			defer waitGroup.Done()
+			address, err := source.GetAddress(ctx)
+			results[index] = &sourceResult{
+				source:  source.GetName(),
+				address: address,
+				err:     err,
+			}
+		}(index, source)
+	}
+
+	waitGroup.Wait()
+
+	return results
+}
+
+// logDisagreement logs the addresses reported by every source.
+func (resolver *Resolver) logDisagreement(results []*sourceResult) {
+	var reports []string
+
+	for _, result := range results {
+		if result.err != nil {
+			continue
+		}
+		reports = append(reports, fmt.Sprintf("%s=%s", result.source, result.address))
+	}
+
+	sort.Strings(reports)
+
+	log.WarnfWithFields(
+		"address sources disagree: %s",
+		log.FieldsMap{
+			"source": "address",
+		},
+		strings.Join(reports, ", "),
+	)
+}
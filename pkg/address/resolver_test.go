@@ -0,0 +1,157 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+type stubSource struct {
+	name    string
+	address netip.Addr
+	err     error
+}
+
+func (source *stubSource) GetName() string {
+	return source.name
+}
+
+func (source *stubSource) GetAddress(_ context.Context) (netip.Addr, error) {
+	return source.address, source.err
+}
+
+func TestParseStrategy(t *testing.T) {
+	tests := []struct {
+		input  string
+		output Strategy
+		err    bool
+	}{
+		{"", StrategyFirstSuccess, false},
+		{"first-success", StrategyFirstSuccess, false},
+		{"FIRST-SUCCESS", StrategyFirstSuccess, false},
+		{"majority", StrategyMajority, false},
+		{"quorum", StrategyMajority, false},
+		{"random", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := ParseStrategy(test.input)
+		if err != nil && !test.err {
+			t.Errorf("expected no error for input %s but got %v", test.input, err)
+		}
+		if err == nil && test.err {
+			t.Errorf("expected an error for input %s but got none", test.input)
+		}
+		if result != test.output {
+			t.Errorf("for input %s, expected %v but got %v", test.input, test.output, result)
+		}
+	}
+}
+
+func TestResolveFirstSuccessFallsBack(t *testing.T) {
+	resolver := NewResolver(
+		StrategyFirstSuccess,
+		0,
+		&stubSource{name: "first", err: errors.New("timeout")},
+		&stubSource{name: "second", address: netip.MustParseAddr("192.0.2.1")},
+		&stubSource{name: "third", address: netip.MustParseAddr("192.0.2.2")},
+	)
+
+	result, err := resolver.Resolve()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if result.String() != "192.0.2.1" {
+		t.Errorf("Expected '192.0.2.1' but got '%s'", result)
+	}
+}
+
+func TestResolveFirstSuccessAllFailed(t *testing.T) {
+	resolver := NewResolver(
+		StrategyFirstSuccess,
+		0,
+		&stubSource{name: "first", err: errors.New("timeout")},
+	)
+
+	if _, err := resolver.Resolve(); !errors.Is(err, ErrNoAddress) {
+		t.Errorf("Expected '%v' but got '%v'", ErrNoAddress, err)
+	}
+}
+
+func TestResolveWithoutSources(t *testing.T) {
+	resolver := NewResolver(StrategyFirstSuccess, 0)
+
+	if _, err := resolver.Resolve(); !errors.Is(err, ErrNoSources) {
+		t.Errorf("Expected '%v' but got '%v'", ErrNoSources, err)
+	}
+}
+
+func TestResolveMajority(t *testing.T) {
+	resolver := NewResolver(
+		StrategyMajority,
+		0,
+		&stubSource{name: "first", address: netip.MustParseAddr("192.0.2.1")},
+		&stubSource{name: "second", address: netip.MustParseAddr("192.0.2.2")},
+		&stubSource{name: "third", address: netip.MustParseAddr("192.0.2.1")},
+	)
+
+	result, err := resolver.Resolve()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if result.String() != "192.0.2.1" {
+		t.Errorf("Expected '192.0.2.1' but got '%s'", result)
+	}
+}
+
+func TestResolveMajorityWithoutAgreement(t *testing.T) {
+	resolver := NewResolver(
+		StrategyMajority,
+		0,
+		&stubSource{name: "first", address: netip.MustParseAddr("192.0.2.1")},
+		&stubSource{name: "second", address: netip.MustParseAddr("192.0.2.2")},
+		&stubSource{name: "third", err: errors.New("timeout")},
+	)
+
+	if _, err := resolver.Resolve(); !errors.Is(err, ErrNoMajority) {
+		t.Errorf("Expected '%v' but got '%v'", ErrNoMajority, err)
+	}
+}
+
+func TestResolveMajorityWithExplicitQuorum(t *testing.T) {
+	resolver := NewResolver(
+		StrategyMajority,
+		1,
+		&stubSource{name: "first", address: netip.MustParseAddr("192.0.2.1")},
+		&stubSource{name: "second", err: errors.New("timeout")},
+		&stubSource{name: "third", err: errors.New("timeout")},
+	)
+
+	result, err := resolver.Resolve()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if result.String() != "192.0.2.1" {
+		t.Errorf("Expected '192.0.2.1' but got '%s'", result)
+	}
+}
+
+func TestResolveWithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resolver := NewResolver(
+		StrategyFirstSuccess,
+		0,
+		&stubSource{name: "first", err: errors.New("timeout")},
+		&stubSource{name: "second", address: netip.MustParseAddr("192.0.2.1")},
+	)
+
+	if _, err := resolver.ResolveWithContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected '%v' but got '%v'", context.Canceled, err)
+	}
+}
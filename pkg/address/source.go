@@ -0,0 +1,15 @@
+package address
+
+import (
+	"context"
+	"net/netip"
+)
+
+// Source is the definition of an address source.
+type Source interface {
+	// GetName returns the name of the source.
+	GetName() string
+	// GetAddress returns the address reported by the source.
+	// The source has to give up when the context is done.
+	GetAddress(ctx context.Context) (netip.Addr, error)
+}
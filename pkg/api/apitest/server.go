@@ -0,0 +1,492 @@
+// Package apitest provides an in-memory fake of the subset of the Cloudflare v4 API used by the application.
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// Token is the API token accepted by the server.
+	Token = "apitest-token"
+	// Email is the E-Mail accepted by the server together with the Global API Key.
+	Email = "administrator@example.com"
+	// Key is the Global API Key accepted by the server.
+	Key = "apitest-key"
+	// BasePath is the path the API is served under.
+	BasePath = "/client/v4"
+	// DefaultPerPage is the number of results per page used when the request does not define one.
+	DefaultPerPage = 20
+)
+
+// Request is the definition of a request received by the server.
+type Request struct {
+	// Method is the HTTP method of the request.
+	Method string
+	// Path is the path of the request relative to the base path.
+	Path string
+	// Query is the query string parameters of the request.
+	Query url.Values
+}
+
+// Failure is the definition of an error injected into the responses of the server.
+type Failure struct {
+	// Method is the HTTP method of the failing requests, any method matches when it is empty.
+	Method string
+	// Path is the path of the failing requests relative to the base path, any path matches when it is empty.
+	Path string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Errors is the list of errors reported in the response.
+	Errors []*entities.Error
+	// RetryAfter is the value of the Retry-After header of the response.
+	RetryAfter string
+	// Times is the number of requests which fail, 0 means once.
+	Times int
+}
+
+// Server is the fake Cloudflare API server.
+type Server struct {
+	// server is the underlying HTTP test server.
+	server *httptest.Server
+	// mutex guards the state of the server.
+	mutex sync.Mutex
+	// zones is the list of zones.
+	zones []*entities.Zone
+	// records is the list of records of every zone, keyed by the zone identifier.
+	records map[string][]*entities.Record
+	// failures is the list of failures which are not exhausted yet.
+	failures []*Failure
+	// requests is the list of requests received by the server.
+	requests []*Request
+	// sequence is the sequence used to generate the identifiers.
+	sequence int
+}
+
+// NewServer starts and returns a new fake Cloudflare API server.
+func NewServer() *Server {
+	server := &Server{
+		records: make(map[string][]*entities.Record),
+	}
+
+	server.server = httptest.NewServer(http.HandlerFunc(server.handle))
+
+	return server
+}
+
+// Close shuts the server down.
+func (server *Server) Close() {
+	server.server.Close()
+}
+
+// GetURL returns the base URL of the API, which can be used as the `api.base_url` configuration value.
+func (server *Server) GetURL() string {
+	return server.server.URL + BasePath
+}
+
+// AddZone adds a zone with the given name and returns it.
+func (server *Server) AddZone(name string) *entities.Zone {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	zone := &entities.Zone{
+		ID:   server.nextID(),
+		Name: name,
+	}
+	server.zones = append(server.zones, zone)
+
+	return copyZone(zone)
+}
+
+// AddRecord adds the record to the zone with the given name and returns it.
+func (server *Server) AddRecord(zoneName string, record *entities.Record) *entities.Record {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	zone := server.findZone(zoneName)
+	if zone == nil {
+		panic(fmt.Sprintf("apitest: zone `%s` does not exist", zoneName))
+	}
+
+	created := copyRecord(record)
+	created.ID = server.nextID()
+	created.ZoneID = zone.ID
+	created.ZoneName = zone.Name
+	server.records[zone.ID] = append(server.records[zone.ID], created)
+
+	return copyRecord(created)
+}
+
+// GetRecords returns the records of the zone with the given name.
+func (server *Server) GetRecords(zoneName string) []*entities.Record {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	zone := server.findZone(zoneName)
+	if zone == nil {
+		return nil
+	}
+
+	var records []*entities.Record
+	for _, record := range server.records[zone.ID] {
+		records = append(records, copyRecord(record))
+	}
+
+	return records
+}
+
+// Fail injects the failure into the responses of the server.
+func (server *Server) Fail(failure *Failure) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if failure.Times < 1 {
+		failure.Times = 1
+	}
+
+	server.failures = append(server.failures, failure)
+}
+
+// GetRequests returns the requests received by the server.
+func (server *Server) GetRequests() []*Request {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return append([]*Request{}, server.requests...)
+}
+
+// CountRequests returns the number of requests with the given method received by the server, any method matches when it is empty.
+func (server *Server) CountRequests(method string) int {
+	count := 0
+	for _, request := range server.GetRequests() {
+		if method == "" || request.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// ResetRequests forgets the requests received by the server.
+func (server *Server) ResetRequests() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.requests = nil
+}
+
+// handle handles the requests to the server.
+func (server *Server) handle(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	path := strings.TrimPrefix(request.URL.Path, BasePath)
+	server.requests = append(server.requests, &Request{
+		Method: request.Method,
+		Path:   path,
+		Query:  request.URL.Query(),
+	})
+
+	if failure := server.takeFailure(request.Method, path); failure != nil {
+		if failure.RetryAfter != "" {
+			writer.Header().Set("Retry-After", failure.RetryAfter)
+		}
+		writeError(writer, failure.StatusCode, failure.Errors...)
+		return
+	}
+
+	if !strings.HasPrefix(request.URL.Path, BasePath+"/") {
+		writeError(writer, http.StatusNotFound, &entities.Error{Code: 7000, Message: "No route for that URI"})
+		return
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case request.Method == http.MethodGet && path == "/user/tokens/verify":
+		server.handleVerifyToken(writer, request)
+	case request.Method == http.MethodGet && path == "/user":
+		server.handleUser(writer, request)
+	case !server.isAuthorized(request):
+		writeError(writer, http.StatusForbidden, &entities.Error{Code: 10000, Message: "Authentication error"})
+	case request.Method == http.MethodGet && path == "/zones":
+		server.handleListZones(writer, request)
+	case len(segments) == 3 && segments[0] == "zones" && segments[2] == "dns_records":
+		server.handleRecords(writer, request, segments[1])
+	case len(segments) == 4 && segments[0] == "zones" && segments[2] == "dns_records":
+		server.handleRecord(writer, request, segments[1], segments[3])
+	default:
+		writeError(writer, http.StatusNotFound, &entities.Error{Code: 7000, Message: "No route for that URI"})
+	}
+}
+
+// handleVerifyToken handles the token verify API.
+func (server *Server) handleVerifyToken(writer http.ResponseWriter, request *http.Request) {
+	if request.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(writer, http.StatusUnauthorized, &entities.Error{Code: 1000, Message: "Invalid API Token"})
+		return
+	}
+
+	writeResult(writer, http.StatusOK, &entities.TokenVerify{ID: "apitest", Status: "active"}, nil)
+}
+
+// handleUser handles the user API.
+func (server *Server) handleUser(writer http.ResponseWriter, request *http.Request) {
+	if !server.isAuthorized(request) {
+		writeError(writer, http.StatusBadRequest, &entities.Error{Code: 9103, Message: "Unknown X-Auth-Key or X-Auth-Email"})
+		return
+	}
+
+	writeResult(writer, http.StatusOK, &entities.User{ID: "apitest", Email: Email}, nil)
+}
+
+// handleListZones handles the zone list API.
+func (server *Server) handleListZones(writer http.ResponseWriter, request *http.Request) {
+	name := request.URL.Query().Get("name")
+
+	var zones []interface{}
+	for _, zone := range server.zones {
+		if name == "" || strings.EqualFold(zone.Name, name) {
+			zones = append(zones, copyZone(zone))
+		}
+	}
+
+	writePage(writer, request, zones)
+}
+
+// handleRecords handles the record list and record create APIs.
+func (server *Server) handleRecords(writer http.ResponseWriter, request *http.Request, zoneID string) {
+	zone := server.findZoneByID(zoneID)
+	if zone == nil {
+		writeError(writer, http.StatusNotFound, &entities.Error{Code: 7003, Message: "Could not route to /zones/" + zoneID + "/dns_records, perhaps your object identifier is invalid?"})
+		return
+	}
+
+	switch request.Method {
+	case http.MethodGet:
+		name := request.URL.Query().Get("name")
+		recordType := request.URL.Query().Get("type")
+
+		var records []interface{}
+		for _, record := range server.records[zone.ID] {
+			if (name == "" || strings.EqualFold(record.Name, name)) && (recordType == "" || strings.EqualFold(record.Type, recordType)) {
+				records = append(records, copyRecord(record))
+			}
+		}
+
+		writePage(writer, request, records)
+	case http.MethodPost:
+		record := &entities.Record{}
+		if err := json.NewDecoder(request.Body).Decode(record); err != nil {
+			writeError(writer, http.StatusBadRequest, &entities.Error{Code: 9207, Message: "Request body is invalid."})
+			return
+		}
+
+		for _, existing := range server.records[zone.ID] {
+			if strings.EqualFold(existing.Name, record.Name) && strings.EqualFold(existing.Type, record.Type) && existing.Content == record.Content {
+				writeError(writer, http.StatusBadRequest, &entities.Error{Code: 81057, Message: "Record already exists."})
+				return
+			}
+		}
+
+		record.ID = server.nextID()
+		record.ZoneID = zone.ID
+		record.ZoneName = zone.Name
+		record.TTL = getTTL(record)
+		server.records[zone.ID] = append(server.records[zone.ID], record)
+
+		writeResult(writer, http.StatusOK, copyRecord(record), nil)
+	default:
+		writeError(writer, http.StatusMethodNotAllowed, &entities.Error{Code: 10405, Message: "Method not allowed"})
+	}
+}
+
+// handleRecord handles the record update and record delete APIs.
+func (server *Server) handleRecord(writer http.ResponseWriter, request *http.Request, zoneID string, recordID string) {
+	records := server.records[zoneID]
+
+	index := -1
+	for candidate, record := range records {
+		if record.ID == recordID {
+			index = candidate
+			break
+		}
+	}
+
+	if index < 0 {
+		writeError(writer, http.StatusNotFound, &entities.Error{Code: 81044, Message: "Record does not exist."})
+		return
+	}
+
+	switch request.Method {
+	case http.MethodPut:
+		record := &entities.Record{}
+		if err := json.NewDecoder(request.Body).Decode(record); err != nil {
+			writeError(writer, http.StatusBadRequest, &entities.Error{Code: 9207, Message: "Request body is invalid."})
+			return
+		}
+
+		record.ID = records[index].ID
+		record.ZoneID = records[index].ZoneID
+		record.ZoneName = records[index].ZoneName
+		record.TTL = getTTL(record)
+		records[index] = record
+
+		writeResult(writer, http.StatusOK, copyRecord(record), nil)
+	case http.MethodDelete:
+		server.records[zoneID] = append(records[:index:index], records[index+1:]...)
+
+		writeResult(writer, http.StatusOK, map[string]string{"id": recordID}, nil)
+	default:
+		writeError(writer, http.StatusMethodNotAllowed, &entities.Error{Code: 10405, Message: "Method not allowed"})
+	}
+}
+
+// isAuthorized checks if the request carries valid credentials.
+func (server *Server) isAuthorized(request *http.Request) bool {
+	if request.Header.Get("Authorization") == "Bearer "+Token {
+		return true
+	}
+
+	return request.Header.Get("X-Auth-Email") == Email && request.Header.Get("X-Auth-Key") == Key
+}
+
+// takeFailure returns the first failure matching the request and consumes it.
+func (server *Server) takeFailure(method string, path string) *Failure {
+	for index, failure := range server.failures {
+		if (failure.Method == "" || failure.Method == method) && (failure.Path == "" || failure.Path == path) {
+			failure.Times--
+			if failure.Times == 0 {
+				server.failures = append(server.failures[:index:index], server.failures[index+1:]...)
+			}
+			return failure
+		}
+	}
+
+	return nil
+}
+
+// findZone returns the zone with the given name.
+func (server *Server) findZone(name string) *entities.Zone {
+	for _, zone := range server.zones {
+		if strings.EqualFold(zone.Name, name) {
+			return zone
+		}
+	}
+	return nil
+}
+
+// findZoneByID returns the zone with the given identifier.
+func (server *Server) findZoneByID(id string) *entities.Zone {
+	for _, zone := range server.zones {
+		if zone.ID == id {
+			return zone
+		}
+	}
+	return nil
+}
+
+// nextID returns a new identifier.
+func (server *Server) nextID() string {
+	server.sequence++
+	return fmt.Sprintf("%032x", server.sequence)
+}
+
+// writePage writes the page of the results requested by the page and per_page parameters.
+func writePage(writer http.ResponseWriter, request *http.Request, results []interface{}) {
+	page, err := strconv.Atoi(request.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(request.URL.Query().Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = DefaultPerPage
+	}
+
+	start := (page - 1) * perPage
+	if start > len(results) {
+		start = len(results)
+	}
+
+	end := start + perPage
+	if end > len(results) {
+		end = len(results)
+	}
+
+	pageResults := append([]interface{}{}, results[start:end]...)
+
+	writeResult(writer, http.StatusOK, pageResults, &entities.ResultInfo{
+		Count:      len(pageResults),
+		Page:       page,
+		PerPage:    perPage,
+		TotalCount: len(results),
+	})
+}
+
+// writeResult writes the successful response.
+func writeResult(writer http.ResponseWriter, statusCode int, result interface{}, resultInfo *entities.ResultInfo) {
+	response := map[string]interface{}{
+		"success":  true,
+		"errors":   []*entities.Error{},
+		"messages": []*entities.Message{},
+		"result":   result,
+	}
+
+	if resultInfo != nil {
+		response["result_info"] = resultInfo
+	}
+
+	writeJSON(writer, statusCode, response)
+}
+
+// writeError writes the failed response.
+func writeError(writer http.ResponseWriter, statusCode int, errors ...*entities.Error) {
+	if errors == nil {
+		errors = []*entities.Error{}
+	}
+
+	writeJSON(writer, statusCode, map[string]interface{}{
+		"success":  false,
+		"errors":   errors,
+		"messages": []*entities.Message{},
+		"result":   nil,
+	})
+}
+
+// writeJSON writes the response as JSON.
+func writeJSON(writer http.ResponseWriter, statusCode int, response interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("CF-Ray", "0000000000000000-TST")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// copyZone returns a copy of the zone.
+func copyZone(zone *entities.Zone) *entities.Zone {
+	copied := *zone
+	return &copied
+}
+
+// getTTL returns the TTL the record is stored with, proxied records always have the automatic TTL like on Cloudflare.
+func getTTL(record *entities.Record) int {
+	if record.Proxied {
+		return 1
+	}
+	return record.TTL
+}
+
+// copyRecord returns a copy of the record.
+func copyRecord(record *entities.Record) *entities.Record {
+	copied := *record
+	if record.Tags != nil {
+		copied.Tags = append([]string{}, record.Tags...)
+	}
+	return &copied
+}
@@ -2,66 +2,188 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/darki73/goflaresync/pkg/api/entities"
 	"github.com/darki73/goflaresync/pkg/configuration"
+	"github.com/darki73/goflaresync/pkg/configuration/cloudflare"
+	"github.com/darki73/goflaresync/pkg/configuration/provider"
 	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/transport"
 	"github.com/darki73/goflaresync/pkg/version"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
 // API is the definition of the Cloudflare API.
+// It implements the provider.Provider interface.
 type API struct {
+	// name is the name of the provider instance.
+	name string
+	// authMode is the authentication mode of the Cloudflare API.
+	authMode string
 	// email is the email of the Cloudflare API.
 	email string
 	// token is the token of the Cloudflare API.
 	token string
+	// key is the Global API Key of the Cloudflare API.
+	key string
 	// userAgent is the user agent of the Cloudflare API.
 	userAgent string
 	// baseURL is the base URL of the Cloudflare API.
 	baseURL string
 	// authenticated is a flag that indicates if the Cloudflare API is authenticated.
 	authenticated bool
+	// perPage is the number of results requested per page when listing zones and records.
+	perPage int
+	// httpClient is the HTTP client used to send the requests.
+	httpClient *http.Client
 }
 
 // NewClient returns a new Cloudflare API client.
 func NewClient() (*API, error) {
+	return NewClientWithContext(context.Background())
+}
+
+// NewClientWithContext returns a new Cloudflare API client, authentication is aborted when the context is done.
+func NewClientWithContext(ctx context.Context) (*API, error) {
+	return NewClientWithCredentials(ctx, provider.DefaultName, configuration.GetConfiguration().GetCredentials())
+}
+
+// NewClientWithCredentials returns a new Cloudflare API client for the named provider instance using the given credentials.
+// Authentication is aborted when the context is done.
+func NewClientWithCredentials(ctx context.Context, name string, config *cloudflare.Configuration) (*API, error) {
 	userAgent := fmt.Sprintf(
 		"goflaresync/%s-%s",
 		version.GetVersion(),
 		version.GetCommit(),
 	)
 
-	config := configuration.GetConfiguration().GetCredentials()
+	apiConfig := configuration.GetConfiguration().GetAPI()
+
+	baseTransport, err := transport.NewFromConfiguration()
+	if err != nil {
+		return nil, err
+	}
 
 	api := &API{
+		name:          name,
+		authMode:      config.GetAuthMode(),
 		email:         config.GetEmail(),
 		token:         config.GetToken(),
+		key:           config.GetKey(),
 		userAgent:     userAgent,
-		baseURL:       "https://api.cloudflare.com/client/v4",
+		baseURL:       apiConfig.GetBaseURL(),
 		authenticated: false,
+		perPage:       apiConfig.GetPerPage(),
+		// The timeout is applied to every attempt by the transport, so a hanging attempt does not use up the retries.
+		httpClient: &http.Client{
+			Transport: &retryTransport{
+				base:          baseTransport,
+				limiter:       newRateLimiter(apiConfig.GetRateLimit(), apiConfig.GetRateBurst()),
+				maxRetries:    apiConfig.GetMaxRetries(),
+				minBackoff:    apiConfig.GetRetryMinBackoff(),
+				maxBackoff:    apiConfig.GetRetryMaxBackoff(),
+				maxRetryAfter: apiConfig.GetMaxRetryAfter(),
+				timeout:       apiConfig.GetTimeout(),
+			},
+		},
 	}
 
-	if err := api.Authenticate(); err != nil {
+	if err := api.AuthenticateWithContext(ctx); err != nil {
 		return nil, err
 	}
 
 	return api, nil
 }
 
+// GetName returns the name of the provider instance.
+func (api *API) GetName() string {
+	return api.name
+}
+
 // Authenticate authenticates the Cloudflare API.
 func (api *API) Authenticate() error {
+	return api.AuthenticateWithContext(context.Background())
+}
+
+// AuthenticateWithContext authenticates the Cloudflare API, the request is aborted when the context is done.
+// API tokens are verified with the token verify API, the Global API Key is verified by retrieving the user.
+func (api *API) AuthenticateWithContext(ctx context.Context) error {
+	log.DebugfWithFields(
+		"attempting to authenticate user with provided credentials using `%s` authentication mode",
+		log.FieldsMap{
+			"source": "api",
+			"action": "authenticate",
+		},
+		api.getAuthMode(),
+	)
+
+	if err := api.validateCredentials(); err != nil {
+		return err
+	}
+
+	var err error
+
+	switch api.getAuthMode() {
+	case cloudflare.AuthModeKey:
+		err = api.verifyKey(ctx)
+	default:
+		err = api.verifyToken(ctx)
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			log.DebugWithFields(
+				"failed to authenticate user due to invalid credentials",
+				log.FieldsMap{
+					"source": "api",
+					"action": "authenticate",
+				},
+			)
+		}
+		return err
+	}
+
+	api.authenticated = true
+
 	log.DebugWithFields(
-		"aAttempting to authenticate user with provided credentials",
+		"successfully authenticated user",
 		log.FieldsMap{
 			"source": "api",
 			"action": "authenticate",
 		},
 	)
-	body, err := api.query(http.MethodGet, "user/tokens/verify", nil)
+
+	return nil
+}
+
+// validateCredentials checks if the credentials required by the authentication mode are set.
+func (api *API) validateCredentials() error {
+	switch api.getAuthMode() {
+	case cloudflare.AuthModeToken:
+		if api.getToken() == "" {
+			return fmt.Errorf("%w: token is required in `%s` mode", ErrMissingCredentials, cloudflare.AuthModeToken)
+		}
+	case cloudflare.AuthModeKey:
+		if api.getEmail() == "" || api.getKey() == "" {
+			return fmt.Errorf("%w: email and key are required in `%s` mode", ErrMissingCredentials, cloudflare.AuthModeKey)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownAuthMode, api.getAuthMode())
+	}
+
+	return nil
+}
+
+// verifyToken verifies that the API token is valid and active.
+func (api *API) verifyToken(ctx context.Context) error {
+	body, err := api.query(ctx, http.MethodGet, "user/tokens/verify", nil, nil)
 	if err != nil {
 		return err
 	}
@@ -72,14 +194,7 @@ func (api *API) Authenticate() error {
 		return err
 	}
 
-	if !response.Success {
-		log.DebugWithFields(
-			"failed to authenticate user due to invalid credentials",
-			log.FieldsMap{
-				"source": "api",
-				"action": "authenticate",
-			},
-		)
+	if !response.Success || response.Result == nil {
 		return ErrInvalidCredentials
 	}
 
@@ -94,66 +209,246 @@ func (api *API) Authenticate() error {
 		return ErrTokenInactive
 	}
 
-	api.authenticated = true
+	return nil
+}
 
-	log.DebugWithFields(
-		"successfully authenticated user",
-		log.FieldsMap{
-			"source": "api",
-			"action": "authenticate",
-		},
-	)
+// verifyKey verifies that the E-Mail and the Global API Key are valid.
+func (api *API) verifyKey(ctx context.Context) error {
+	body, err := api.query(ctx, http.MethodGet, "user", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	response := &entities.UserResponse{}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return err
+	}
+
+	if !response.Success || response.Result == nil {
+		return ErrInvalidCredentials
+	}
 
 	return nil
 }
 
-// ListZones returns a list of zones.
-func (api *API) ListZones() (*entities.ZoneListResponse, error) {
+// ListZones returns the list of all zones.
+func (api *API) ListZones() ([]*entities.Zone, error) {
+	return api.ListZonesWithContext(context.Background())
+}
+
+// ListZonesWithContext returns the list of all zones, the requests are aborted when the context is done.
+func (api *API) ListZonesWithContext(ctx context.Context) ([]*entities.Zone, error) {
+	var zones []*entities.Zone
+
+	err := api.WalkZonesWithContext(ctx, func(zone *entities.Zone) error {
+		zones = append(zones, zone)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+// WalkZones calls the callback for every zone, fetching the pages as they are needed.
+// Walking stops when the callback returns an error, ErrStopWalk stops it without an error.
+func (api *API) WalkZones(callback func(zone *entities.Zone) error) error {
+	return api.WalkZonesWithContext(context.Background(), callback)
+}
+
+// WalkZonesWithContext calls the callback for every zone, fetching the pages as they are needed.
+// Walking stops when the callback returns an error or the context is done.
+func (api *API) WalkZonesWithContext(ctx context.Context, callback func(zone *entities.Zone) error) error {
+	if !api.isAuthenticated() {
+		return ErrNotAuthenticated
+	}
+
+	return api.walkZones(ctx, nil, callback)
+}
+
+// FindZone returns the zone with the given name.
+func (api *API) FindZone(name string) (*entities.Zone, error) {
+	return api.FindZoneWithContext(context.Background(), name)
+}
+
+// FindZoneWithContext returns the zone with the given name, the requests are aborted when the context is done.
+func (api *API) FindZoneWithContext(ctx context.Context, name string) (*entities.Zone, error) {
 	if !api.isAuthenticated() {
 		return nil, ErrNotAuthenticated
 	}
 
-	body, err := api.query(http.MethodGet, "zones", nil)
+	var found *entities.Zone
+
+	err := api.walkZones(ctx, url.Values{"name": {name}}, func(zone *entities.Zone) error {
+		if strings.EqualFold(zone.Name, name) {
+			found = zone
+			return ErrStopWalk
+		}
+		return nil
+	})
+
 	if err != nil {
 		return nil, err
 	}
 
-	response := &entities.ZoneListResponse{}
+	if found == nil {
+		return nil, ErrZoneNotFound
+	}
 
-	if err := json.Unmarshal(body, response); err != nil {
+	return found, nil
+}
+
+// walkZones calls the callback for every zone matching the parameters.
+func (api *API) walkZones(ctx context.Context, parameters url.Values, callback func(zone *entities.Zone) error) error {
+	return api.paginate(ctx, "zones", parameters, func(body []byte) (*entities.ResultInfo, error) {
+		response := &entities.ZoneListResponse{}
+
+		if err := json.Unmarshal(body, response); err != nil {
+			return nil, err
+		}
+
+		for _, zone := range response.Result {
+			if err := callback(zone); err != nil {
+				return nil, err
+			}
+		}
+
+		return response.ResultInfo, nil
+	})
+}
+
+// ListRecords returns the list of all records of the zone.
+func (api *API) ListRecords(zone *entities.Zone) ([]*entities.Record, error) {
+	return api.ListRecordsWithContext(context.Background(), zone)
+}
+
+// ListRecordsWithContext returns the list of all records of the zone, the requests are aborted when the context is done.
+func (api *API) ListRecordsWithContext(ctx context.Context, zone *entities.Zone) ([]*entities.Record, error) {
+	var records []*entities.Record
+
+	err := api.WalkRecordsWithContext(ctx, zone, func(record *entities.Record) error {
+		records = append(records, record)
+		return nil
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
-	return response, nil
+	return records, nil
+}
+
+// WalkRecords calls the callback for every record of the zone, fetching the pages as they are needed.
+// Walking stops when the callback returns an error, ErrStopWalk stops it without an error.
+func (api *API) WalkRecords(zone *entities.Zone, callback func(record *entities.Record) error) error {
+	return api.WalkRecordsWithContext(context.Background(), zone, callback)
+}
+
+// WalkRecordsWithContext calls the callback for every record of the zone, fetching the pages as they are needed.
+// Walking stops when the callback returns an error or the context is done.
+func (api *API) WalkRecordsWithContext(ctx context.Context, zone *entities.Zone, callback func(record *entities.Record) error) error {
+	if !api.isAuthenticated() {
+		return ErrNotAuthenticated
+	}
+
+	return api.walkRecords(ctx, zone, nil, callback)
+}
+
+// FindRecords returns the records of the zone with the given name and type.
+func (api *API) FindRecords(zone *entities.Zone, name string, recordType string) ([]*entities.Record, error) {
+	return api.FindRecordsWithContext(context.Background(), zone, name, recordType)
+}
+
+// FindRecordsWithContext returns the records of the zone with the given name and type, the requests are aborted when the context is done.
+func (api *API) FindRecordsWithContext(ctx context.Context, zone *entities.Zone, name string, recordType string) ([]*entities.Record, error) {
+	if !api.isAuthenticated() {
+		return nil, ErrNotAuthenticated
+	}
+
+	records := []*entities.Record{}
+
+	err := api.walkRecords(ctx, zone, url.Values{"name": {name}, "type": {recordType}}, func(record *entities.Record) error {
+		if strings.EqualFold(record.Name, name) && strings.EqualFold(record.Type, recordType) {
+			records = append(records, record)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// walkRecords calls the callback for every record of the zone matching the parameters.
+func (api *API) walkRecords(ctx context.Context, zone *entities.Zone, parameters url.Values, callback func(record *entities.Record) error) error {
+	return api.paginate(ctx, fmt.Sprintf("zones/%s/dns_records", zone.ID), parameters, func(body []byte) (*entities.ResultInfo, error) {
+		response := &entities.RecordListResponse{}
+
+		if err := json.Unmarshal(body, response); err != nil {
+			return nil, err
+		}
+
+		for _, record := range response.Result {
+			if err := callback(record); err != nil {
+				return nil, err
+			}
+		}
+
+		return response.ResultInfo, nil
+	})
+}
+
+// UpdateRecord updates a record and returns the updated record.
+func (api *API) UpdateRecord(zone *entities.Zone, record *entities.Record) (*entities.Record, error) {
+	return api.UpdateRecordWithContext(context.Background(), zone, record)
 }
 
-// ListRecords returns a list of records.
-func (api *API) ListRecords(zone *entities.Zone) (*entities.RecordListResponse, error) {
+// UpdateRecordWithContext updates a record and returns the updated record, the request is aborted when the context is done.
+func (api *API) UpdateRecordWithContext(ctx context.Context, zone *entities.Zone, record *entities.Record) (*entities.Record, error) {
 	if !api.isAuthenticated() {
 		return nil, ErrNotAuthenticated
 	}
 
-	body, err := api.query(http.MethodGet, fmt.Sprintf("zones/%s/dns_records", zone.ID), nil)
+	body, err := api.query(ctx, http.MethodPut, fmt.Sprintf("zones/%s/dns_records/%s", zone.ID, record.ID), nil, entities.RecordUpdateRequest{
+		Content: record.Content,
+		Name:    record.Name,
+		Proxied: record.Proxied,
+		Type:    record.Type,
+		Comment: record.Comment,
+		TTL:     record.TTL,
+		Tags:    record.Tags,
+	})
+
 	if err != nil {
 		return nil, err
 	}
 
-	response := &entities.RecordListResponse{}
+	response := &entities.RecordUpdateResponse{}
 
 	if err := json.Unmarshal(body, response); err != nil {
 		return nil, err
 	}
 
-	return response, nil
+	return getRecordResult(zone, response.Result)
+}
+
+// CreateRecord creates a record and returns the created record.
+func (api *API) CreateRecord(zone *entities.Zone, record *entities.Record) (*entities.Record, error) {
+	return api.CreateRecordWithContext(context.Background(), zone, record)
 }
 
-// UpdateRecord updates a record.
-func (api *API) UpdateRecord(zone *entities.Zone, record *entities.Record) (*entities.RecordUpdateResponse, error) {
+// CreateRecordWithContext creates a record and returns the created record, the request is aborted when the context is done.
+func (api *API) CreateRecordWithContext(ctx context.Context, zone *entities.Zone, record *entities.Record) (*entities.Record, error) {
 	if !api.isAuthenticated() {
 		return nil, ErrNotAuthenticated
 	}
 
-	body, err := api.query(http.MethodPut, fmt.Sprintf("zones/%s/dns_records/%s", zone.ID, record.ID), entities.RecordUpdateRequest{
+	body, err := api.query(ctx, http.MethodPost, fmt.Sprintf("zones/%s/dns_records", zone.ID), nil, entities.RecordCreateRequest{
 		Content: record.Content,
 		Name:    record.Name,
 		Proxied: record.Proxied,
@@ -167,13 +462,56 @@ func (api *API) UpdateRecord(zone *entities.Zone, record *entities.Record) (*ent
 		return nil, err
 	}
 
-	response := &entities.RecordUpdateResponse{}
+	response := &entities.RecordCreateResponse{}
 
 	if err := json.Unmarshal(body, response); err != nil {
 		return nil, err
 	}
 
-	return response, nil
+	return getRecordResult(zone, response.Result)
+}
+
+// DeleteRecord deletes a record.
+func (api *API) DeleteRecord(zone *entities.Zone, record *entities.Record) error {
+	return api.DeleteRecordWithContext(context.Background(), zone, record)
+}
+
+// DeleteRecordWithContext deletes a record, the request is aborted when the context is done.
+func (api *API) DeleteRecordWithContext(ctx context.Context, zone *entities.Zone, record *entities.Record) error {
+	if !api.isAuthenticated() {
+		return ErrNotAuthenticated
+	}
+
+	body, err := api.query(ctx, http.MethodDelete, fmt.Sprintf("zones/%s/dns_records/%s", zone.ID, record.ID), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	response := &entities.RecordDeleteResponse{}
+
+	return json.Unmarshal(body, response)
+}
+
+// getRecordResult returns the record returned by the Cloudflare API along with the zone it belongs to.
+func getRecordResult(zone *entities.Zone, record *entities.Record) (*entities.Record, error) {
+	if record == nil {
+		return nil, ErrEmptyResult
+	}
+
+	if record.ZoneID == "" {
+		record.ZoneID = zone.ID
+	}
+
+	if record.ZoneName == "" {
+		record.ZoneName = zone.Name
+	}
+
+	return record, nil
+}
+
+// getAuthMode returns the authentication mode of the Cloudflare API.
+func (api *API) getAuthMode() string {
+	return api.authMode
 }
 
 // getToken returns the token of the Cloudflare API.
@@ -186,6 +524,11 @@ func (api *API) getEmail() string {
 	return api.email
 }
 
+// getKey returns the Global API Key of the Cloudflare API.
+func (api *API) getKey() string {
+	return api.key
+}
+
 // getUserAgent returns the user agent of the Cloudflare API.
 func (api *API) getUserAgent() string {
 	return api.userAgent
@@ -196,14 +539,33 @@ func (api *API) getBaseURL() string {
 	return api.baseURL
 }
 
+// getPerPage returns the number of results requested per page.
+func (api *API) getPerPage() int {
+	return api.perPage
+}
+
+// getHTTPClient returns the HTTP client used to send the requests.
+func (api *API) getHTTPClient() *http.Client {
+	return api.httpClient
+}
+
 // getHeaders returns the headers of the Cloudflare API.
+// The authentication headers depend on the authentication mode.
 func (api *API) getHeaders() map[string]string {
-	return map[string]string{
-		"Authorization": fmt.Sprintf("Bearer %s", api.getToken()),
-		"X-Auth-Email":  api.getEmail(),
-		"Content-Type":  "application/json",
-		"User-Agent":    api.getUserAgent(),
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"User-Agent":   api.getUserAgent(),
+	}
+
+	switch api.getAuthMode() {
+	case cloudflare.AuthModeKey:
+		headers["X-Auth-Email"] = api.getEmail()
+		headers["X-Auth-Key"] = api.getKey()
+	default:
+		headers["Authorization"] = fmt.Sprintf("Bearer %s", api.getToken())
 	}
+
+	return headers
 }
 
 // isAuthenticated returns a flag that indicates if the Cloudflare API is authenticated.
@@ -211,38 +573,76 @@ func (api *API) isAuthenticated() bool {
 	return api.authenticated
 }
 
+// paginate queries every page of the given path and passes the body of each page to the handler.
+// The handler returns the result information of the page which is used to decide if there are more pages.
+func (api *API) paginate(ctx context.Context, path string, parameters url.Values, handler func(body []byte) (*entities.ResultInfo, error)) error {
+	pageParameters := url.Values{}
+	for key, values := range parameters {
+		pageParameters[key] = values
+	}
+	pageParameters.Set("per_page", strconv.Itoa(api.getPerPage()))
+
+	for page := 1; ; page++ {
+		pageParameters.Set("page", strconv.Itoa(page))
+
+		body, err := api.query(ctx, http.MethodGet, path, pageParameters, nil)
+		if err != nil {
+			return err
+		}
+
+		resultInfo, err := handler(body)
+		if err != nil {
+			if errors.Is(err, ErrStopWalk) {
+				return nil
+			}
+			return err
+		}
+
+		if resultInfo == nil || resultInfo.Count == 0 || resultInfo.Page*resultInfo.PerPage >= resultInfo.TotalCount {
+			return nil
+		}
+
+		log.TracefWithFields(
+			"fetched page %d of `%s`, %d out of %d results",
+			log.FieldsMap{
+				"source": "api",
+			},
+			resultInfo.Page,
+			path,
+			resultInfo.Page*resultInfo.PerPage,
+			resultInfo.TotalCount,
+		)
+	}
+}
+
 // query queries the Cloudflare API.
-func (api *API) query(method string, path string, query interface{}) ([]byte, error) {
-	log.Tracef("[API] Query method called with the following arguments: method=%s, path=%s, query=%v", method, path, query)
+// The parameters are URL-encoded into the query string, the payload is sent as the JSON body.
+func (api *API) query(ctx context.Context, method string, path string, parameters url.Values, payload interface{}) ([]byte, error) {
+	log.Tracef("[API] Query method called with the following arguments: method=%s, path=%s, parameters=%s, payload=%v", method, path, parameters.Encode(), payload)
 	if strings.HasPrefix(path, "/") {
 		path = strings.TrimPrefix(path, "/")
 	}
 
-	url := fmt.Sprintf("%s/%s", api.getBaseURL(), path)
+	endpoint := fmt.Sprintf("%s/%s", api.getBaseURL(), path)
+	if len(parameters) > 0 {
+		endpoint = fmt.Sprintf("%s?%s", endpoint, parameters.Encode())
+	}
 
-	var request *http.Request
-	var err error
+	var body io.Reader
 
 	switch method {
-	case http.MethodGet:
-		if query != nil {
-			queryString, err := json.Marshal(query)
-			if err != nil {
-				return nil, err
-			}
-			url = fmt.Sprintf("%s?%s", url, string(queryString))
-		}
-		request, err = http.NewRequest(method, url, nil)
-	case http.MethodPost, http.MethodPut:
-		jsonData, err := json.Marshal(query)
+	case http.MethodGet, http.MethodDelete:
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		jsonData, err := json.Marshal(payload)
 		if err != nil {
 			return nil, err
 		}
-		request, err = http.NewRequest(method, url, bytes.NewBuffer(jsonData))
+		body = bytes.NewBuffer(jsonData)
 	default:
 		return nil, fmt.Errorf("invalid method: %s", method)
 	}
 
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
 	if err != nil {
 		return nil, err
 	}
@@ -251,17 +651,25 @@ func (api *API) query(method string, path string, query interface{}) ([]byte, er
 		request.Header.Set(key, value)
 	}
 
-	client := &http.Client{}
-
-	response, err := client.Do(request)
+	response, err := api.getHTTPClient().Do(request)
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned non-200 status code: %d", response.StatusCode)
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, newError(response, responseBody)
+	}
+
+	envelope := &entities.Response{}
+	if err := json.Unmarshal(responseBody, envelope); err == nil && !envelope.Success {
+		return nil, newError(response, responseBody)
 	}
 
-	return io.ReadAll(response.Body)
+	return responseBody, nil
 }
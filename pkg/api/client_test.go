@@ -0,0 +1,371 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"github.com/darki73/goflaresync/pkg/configuration/cloudflare"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newPaginatedZonesServer(t *testing.T, total int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		page, _ := strconv.Atoi(request.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(request.URL.Query().Get("per_page"))
+
+		if page < 1 || perPage < 1 {
+			t.Errorf("Expected page and per_page to be set but got '%s'", request.URL.RawQuery)
+		}
+
+		response := &entities.ZoneListResponse{
+			Success: true,
+			Result:  []*entities.Zone{},
+		}
+
+		for index := (page - 1) * perPage; index < page*perPage && index < total; index++ {
+			response.Result = append(response.Result, &entities.Zone{
+				ID:   fmt.Sprintf("zone-%d", index),
+				Name: fmt.Sprintf("example%d.com", index),
+			})
+		}
+
+		response.ResultInfo = &entities.ResultInfo{
+			Count:      len(response.Result),
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+		}
+
+		_ = json.NewEncoder(writer).Encode(response)
+	}))
+}
+
+func newTestClient(baseURL string, perPage int) *API {
+	return &API{
+		baseURL:       baseURL,
+		authenticated: true,
+		perPage:       perPage,
+		httpClient:    &http.Client{},
+	}
+}
+
+func TestListZonesReadsAllPages(t *testing.T) {
+	server := newPaginatedZonesServer(t, 7)
+	defer server.Close()
+
+	zones, err := newTestClient(server.URL, 3).ListZones()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if len(zones) != 7 {
+		t.Fatalf("Expected 7 zones but got %d", len(zones))
+	}
+
+	if zones[6].ID != "zone-6" {
+		t.Errorf("Expected 'zone-6' but got '%s'", zones[6].ID)
+	}
+}
+
+func TestListZonesWithoutResults(t *testing.T) {
+	server := newPaginatedZonesServer(t, 0)
+	defer server.Close()
+
+	zones, err := newTestClient(server.URL, 3).ListZones()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if len(zones) != 0 {
+		t.Errorf("Expected no zones but got %d", len(zones))
+	}
+}
+
+func TestWalkZonesStops(t *testing.T) {
+	server := newPaginatedZonesServer(t, 7)
+	defer server.Close()
+
+	visited := 0
+	err := newTestClient(server.URL, 3).WalkZones(func(zone *entities.Zone) error {
+		visited++
+		if zone.ID == "zone-4" {
+			return ErrStopWalk
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if visited != 5 {
+		t.Errorf("Expected 5 visited zones but got %d", visited)
+	}
+}
+
+func TestFindRecordsUsesQueryParameters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/zones/zone-1/dns_records" {
+			t.Errorf("Expected '/zones/zone-1/dns_records' but got '%s'", request.URL.Path)
+		}
+
+		query := request.URL.Query()
+		if query.Get("name") != "www.example.com" || query.Get("type") != "AAAA" {
+			t.Errorf("Expected name and type filters but got '%s'", request.URL.RawQuery)
+		}
+
+		_ = json.NewEncoder(writer).Encode(&entities.RecordListResponse{
+			Success: true,
+			Result: []*entities.Record{
+				{ID: "record-1", Name: "www.example.com", Type: "AAAA", Content: "2001:db8::1"},
+			},
+			ResultInfo: &entities.ResultInfo{Count: 1, Page: 1, PerPage: 100, TotalCount: 1},
+		})
+	}))
+	defer server.Close()
+
+	records, err := newTestClient(server.URL, 100).FindRecords(&entities.Zone{ID: "zone-1"}, "www.example.com", "AAAA")
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if len(records) != 1 || records[0].ID != "record-1" {
+		t.Errorf("Expected record 'record-1' but got %+v", records)
+	}
+}
+
+func TestFindZoneNotFound(t *testing.T) {
+	server := newPaginatedZonesServer(t, 0)
+	defer server.Close()
+
+	if _, err := newTestClient(server.URL, 100).FindZone("example.com"); err != ErrZoneNotFound {
+		t.Errorf("Expected '%v' but got '%v'", ErrZoneNotFound, err)
+	}
+}
+
+func TestQueryReturnsAPIError(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		authentication bool
+		transient      bool
+		validation     bool
+	}{
+		{
+			name:           "invalid token",
+			status:         http.StatusBadRequest,
+			body:           `{"success":false,"errors":[{"code":9109,"message":"Invalid access token"}],"messages":[]}`,
+			authentication: true,
+		},
+		{
+			name:           "forbidden",
+			status:         http.StatusForbidden,
+			body:           `{"success":false,"errors":[{"code":10000,"message":"Authentication error"}],"messages":[]}`,
+			authentication: true,
+		},
+		{
+			name:           "unauthorized",
+			status:         http.StatusUnauthorized,
+			body:           `{"success":false,"errors":[],"messages":[]}`,
+			authentication: true,
+		},
+		{
+			name:       "forbidden without authentication error code",
+			status:     http.StatusForbidden,
+			body:       `{"success":false,"errors":[],"messages":[]}`,
+			validation: true,
+		},
+		{
+			name:       "invalid content",
+			status:     http.StatusBadRequest,
+			body:       `{"success":false,"errors":[{"code":9005,"message":"Content for A record is invalid."}],"messages":[]}`,
+			validation: true,
+		},
+		{
+			name:       "unsuccessful response with status 200",
+			status:     http.StatusOK,
+			body:       `{"success":false,"errors":[{"code":81057,"message":"Record already exists."}],"messages":[]}`,
+			validation: true,
+		},
+		{
+			name:      "rate limited",
+			status:    http.StatusTooManyRequests,
+			body:      `{"success":false,"errors":[{"code":971,"message":"Please wait and consider throttling your request speed"}],"messages":[]}`,
+			transient: true,
+		},
+		{
+			name:      "gateway error page",
+			status:    http.StatusBadGateway,
+			body:      `<html><body>Bad Gateway</body></html>`,
+			transient: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("CF-Ray", "8a1b2c3d4e5f-AMS")
+				writer.WriteHeader(test.status)
+				_, _ = writer.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			_, err := newTestClient(server.URL, 100).UpdateRecord(&entities.Zone{ID: "zone-1"}, &entities.Record{ID: "record-1"})
+
+			var apiError *Error
+			if !errors.As(err, &apiError) {
+				t.Fatalf("Expected an API error but got %v", err)
+			}
+
+			if apiError.StatusCode != test.status {
+				t.Errorf("Expected status %d but got %d", test.status, apiError.StatusCode)
+			}
+
+			if apiError.RequestID != "8a1b2c3d4e5f-AMS" {
+				t.Errorf("Expected '8a1b2c3d4e5f-AMS' but got '%s'", apiError.RequestID)
+			}
+
+			if apiError.IsAuthenticationError() != test.authentication {
+				t.Errorf("Expected authentication error to be %t but got %t", test.authentication, apiError.IsAuthenticationError())
+			}
+
+			if errors.Is(err, ErrInvalidCredentials) != test.authentication {
+				t.Errorf("Expected matching '%v' to be %t", ErrInvalidCredentials, test.authentication)
+			}
+
+			if apiError.IsTransient() != test.transient {
+				t.Errorf("Expected transient error to be %t but got %t", test.transient, apiError.IsTransient())
+			}
+
+			if apiError.IsValidationError() != test.validation {
+				t.Errorf("Expected validation error to be %t but got %t", test.validation, apiError.IsValidationError())
+			}
+		})
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	err := &Error{
+		StatusCode: http.StatusBadRequest,
+		Errors: []*entities.Error{
+			{Code: 9005, Message: "Content for A record is invalid."},
+			{Code: 9021, Message: "Invalid TTL."},
+		},
+		RequestID: "8a1b2c3d4e5f-AMS",
+	}
+
+	expected := "cloudflare API error (status 400, request 8a1b2c3d4e5f-AMS): 9005 Content for A record is invalid.; 9021 Invalid TTL."
+	if err.Error() != expected {
+		t.Errorf("Expected '%s' but got '%s'", expected, err.Error())
+	}
+}
+
+func TestQueryIsAbortedWhenContextIsCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-request.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := newTestClient(server.URL, 100).FindZoneWithContext(ctx, "example.com")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected '%v' but got '%v'", context.DeadlineExceeded, err)
+	}
+}
+
+func TestAuthenticateUsesAuthMode(t *testing.T) {
+	tests := []struct {
+		name             string
+		client           *API
+		expectedPath     string
+		expectedHeaders  map[string]string
+		forbiddenHeaders []string
+	}{
+		{
+			name:         "token",
+			client:       &API{authMode: cloudflare.AuthModeToken, email: "administrator@example.com", token: "token"},
+			expectedPath: "/user/tokens/verify",
+			expectedHeaders: map[string]string{
+				"Authorization": "Bearer token",
+			},
+			forbiddenHeaders: []string{"X-Auth-Email", "X-Auth-Key"},
+		},
+		{
+			name:         "key",
+			client:       &API{authMode: cloudflare.AuthModeKey, email: "administrator@example.com", key: "key"},
+			expectedPath: "/user",
+			expectedHeaders: map[string]string{
+				"X-Auth-Email": "administrator@example.com",
+				"X-Auth-Key":   "key",
+			},
+			forbiddenHeaders: []string{"Authorization"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				if request.URL.Path != test.expectedPath {
+					t.Errorf("Expected '%s' but got '%s'", test.expectedPath, request.URL.Path)
+				}
+
+				for header, value := range test.expectedHeaders {
+					if request.Header.Get(header) != value {
+						t.Errorf("Expected header '%s' to be '%s' but got '%s'", header, value, request.Header.Get(header))
+					}
+				}
+
+				for _, header := range test.forbiddenHeaders {
+					if request.Header.Get(header) != "" {
+						t.Errorf("Expected header '%s' not to be sent", header)
+					}
+				}
+
+				_, _ = writer.Write([]byte(`{"success":true,"errors":[],"messages":[],"result":{"id":"1","email":"administrator@example.com","status":"active"}}`))
+			}))
+			defer server.Close()
+
+			test.client.baseURL = server.URL
+			test.client.httpClient = &http.Client{}
+
+			if err := test.client.Authenticate(); err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+
+			if !test.client.isAuthenticated() {
+				t.Errorf("Expected the client to be authenticated")
+			}
+		})
+	}
+}
+
+func TestAuthenticateRequiresCredentials(t *testing.T) {
+	tests := []struct {
+		client   *API
+		expected error
+	}{
+		{client: &API{authMode: cloudflare.AuthModeToken}, expected: ErrMissingCredentials},
+		{client: &API{authMode: cloudflare.AuthModeKey, key: "key"}, expected: ErrMissingCredentials},
+		{client: &API{authMode: "password", token: "token"}, expected: ErrUnknownAuthMode},
+	}
+
+	for _, test := range tests {
+		if err := test.client.Authenticate(); !errors.Is(err, test.expected) {
+			t.Errorf("Expected '%v' but got '%v'", test.expected, err)
+		}
+	}
+}
@@ -27,3 +27,13 @@ type ResultInfo struct {
 	// TotalCount is the total number of results returned in the response
 	TotalCount int `json:"total_count"`
 }
+
+// Response is the definition of the envelope shared by all responses
+type Response struct {
+	// Errors is the list of errors
+	Errors []*Error `json:"errors"`
+	// Messages is the list of messages
+	Messages []*Message `json:"messages"`
+	// Success is a flag that indicates if the API call was successful
+	Success bool `json:"success"`
+}
@@ -0,0 +1,19 @@
+package entities
+
+// RecordDelete is the definition of the result of the record delete API.
+type RecordDelete struct {
+	// ID is the identifier of the deleted record.
+	ID string `json:"id"`
+}
+
+// RecordDeleteResponse is the definition of the response of the record delete API.
+type RecordDeleteResponse struct {
+	// Errors is the list of errors.
+	Errors []*Error `json:"errors"`
+	// Messages is the list of messages.
+	Messages []*Message `json:"messages"`
+	// Result is the result of the API call.
+	Result *RecordDelete `json:"result"`
+	// Success is a flag that indicates if the API call was successful.
+	Success bool `json:"success"`
+}
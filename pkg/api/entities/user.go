@@ -0,0 +1,21 @@
+package entities
+
+// User is the definition of the user API.
+type User struct {
+	// ID is the user ID.
+	ID string `json:"id"`
+	// Email is the email of the user.
+	Email string `json:"email"`
+}
+
+// UserResponse is the definition of the response of the user API.
+type UserResponse struct {
+	// Errors is the list of errors.
+	Errors []*Error `json:"errors"`
+	// Messages is the list of messages.
+	Messages []*Message `json:"messages"`
+	// Result is the result of the API call.
+	Result *User `json:"result"`
+	// Success is a flag that indicates if the API call was successful.
+	Success bool `json:"success"`
+}
@@ -1,9 +1,137 @@
 package api
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"github.com/darki73/goflaresync/pkg/provider"
+	"net/http"
+	"strings"
+)
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrTokenInactive      = errors.New("token is inactive")
-	ErrNotAuthenticated   = errors.New("not authenticated")
+	ErrInvalidCredentials   = provider.ErrInvalidCredentials
+	ErrTokenInactive        = errors.New("token is inactive")
+	ErrNotAuthenticated     = errors.New("not authenticated")
+	ErrStopWalk             = errors.New("stop walk")
+	ErrZoneNotFound         = provider.ErrZoneNotFound
+	ErrRequestNotReplayable = errors.New("request body cannot be replayed")
+	ErrMissingCredentials   = errors.New("missing credentials")
+	ErrUnknownAuthMode      = errors.New("unknown authentication mode")
+	ErrEmptyResult          = errors.New("no result was returned")
 )
+
+// authenticationErrorCodes is the list of Cloudflare error codes which indicate an authentication problem.
+var authenticationErrorCodes = []int{
+	6003,  // invalid request headers
+	6111,  // invalid format for Authorization header
+	9103,  // unknown X-Auth-Key or X-Auth-Email
+	9106,  // missing X-Auth-Key or X-Auth-Email
+	9109,  // invalid access token
+	10000, // authentication error
+}
+
+// Error is the definition of an error returned by the Cloudflare API.
+type Error struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Errors is the list of errors reported by the Cloudflare API.
+	Errors []*entities.Error
+	// RequestID is the identifier of the request (the value of the CF-Ray header).
+	RequestID string
+}
+
+// Error returns the description of the error.
+func (err *Error) Error() string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("cloudflare API error (status %d", err.StatusCode))
+	if err.RequestID != "" {
+		builder.WriteString(fmt.Sprintf(", request %s", err.RequestID))
+	}
+	builder.WriteString(")")
+
+	if len(err.Errors) == 0 {
+		builder.WriteString(": ")
+		builder.WriteString(strings.ToLower(http.StatusText(err.StatusCode)))
+		return builder.String()
+	}
+
+	for index, apiError := range err.Errors {
+		if index == 0 {
+			builder.WriteString(": ")
+		} else {
+			builder.WriteString("; ")
+		}
+		builder.WriteString(fmt.Sprintf("%d %s", apiError.Code, apiError.Message))
+	}
+
+	return builder.String()
+}
+
+// GetCodes returns the list of error codes reported by the Cloudflare API.
+func (err *Error) GetCodes() []int {
+	codes := make([]int, 0, len(err.Errors))
+	for _, apiError := range err.Errors {
+		codes = append(codes, apiError.Code)
+	}
+	return codes
+}
+
+// HasCode checks if the Cloudflare API reported the given error code.
+func (err *Error) HasCode(code int) bool {
+	for _, apiError := range err.Errors {
+		if apiError.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAuthenticationError checks if the request was rejected because of the credentials.
+// A forbidden request without an authentication error code is not one, since the token may only lack the permission on a single zone.
+func (err *Error) IsAuthenticationError() bool {
+	if err.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+
+	for _, code := range authenticationErrorCodes {
+		if err.HasCode(code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsTransient checks if the request failed because of a temporary condition and may succeed when retried.
+func (err *Error) IsTransient() bool {
+	return err.StatusCode == http.StatusTooManyRequests || err.StatusCode >= http.StatusInternalServerError
+}
+
+// IsValidationError checks if the request was rejected because of its content.
+func (err *Error) IsValidationError() bool {
+	return !err.IsAuthenticationError() && !err.IsTransient()
+}
+
+// Unwrap returns ErrInvalidCredentials for authentication errors, so they can be matched with errors.Is.
+func (err *Error) Unwrap() error {
+	if err.IsAuthenticationError() {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// newError returns the error described by the response.
+func newError(response *http.Response, body []byte) *Error {
+	envelope := &entities.Response{}
+	// The body is not guaranteed to be JSON (e.g. an error page of a proxy), the status code is enough then.
+	_ = json.Unmarshal(body, envelope)
+
+	return &Error{
+		StatusCode: response.StatusCode,
+		Errors:     envelope.Errors,
+		RequestID:  response.Header.Get("CF-Ray"),
+	}
+}
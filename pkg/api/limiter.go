@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket limiting the rate of the requests.
+type rateLimiter struct {
+	// mutex guards the state of the bucket.
+	mutex sync.Mutex
+	// rate is the number of tokens added to the bucket per second.
+	rate float64
+	// burst is the capacity of the bucket.
+	burst float64
+	// tokens is the number of tokens currently in the bucket, it is negative when tokens are reserved in advance.
+	tokens float64
+	// last is the time the bucket was last refilled.
+	last time.Time
+}
+
+// newRateLimiter returns a new rate limiter, or nil if the rate is not limited.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// wait blocks until a request may be sent or the context is done.
+func (limiter *rateLimiter) wait(ctx context.Context) error {
+	if limiter == nil {
+		return nil
+	}
+
+	delay := limiter.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	return sleep(ctx, delay)
+}
+
+// reserve takes a token out of the bucket and returns the time to wait until the token is available.
+func (limiter *rateLimiter) reserve(now time.Time) time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if !limiter.last.IsZero() {
+		elapsed := now.Sub(limiter.last).Seconds()
+		limiter.tokens = math.Min(limiter.burst, limiter.tokens+elapsed*limiter.rate)
+	}
+	limiter.last = now
+
+	limiter.tokens--
+	if limiter.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-limiter.tokens / limiter.rate * float64(time.Second))
+}
@@ -0,0 +1,16 @@
+package api
+
+import "github.com/darki73/goflaresync/pkg/provider"
+
+// API is used by the watcher as a DNS provider.
+var _ provider.Provider = (*API)(nil)
+
+// SupportsAttribute checks if the record attribute is stored by Cloudflare, which stores all of them.
+func (api *API) SupportsAttribute(attribute string) bool {
+	switch attribute {
+	case provider.AttributeProxied, provider.AttributeTTL, provider.AttributeComment, provider.AttributeTags:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"github.com/darki73/goflaresync/pkg/log"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryTransport is the HTTP transport which limits the rate of the requests and retries the failed ones.
+type retryTransport struct {
+	// base is the transport used to send the requests.
+	base http.RoundTripper
+	// limiter is the client-side rate limiter, nil if the rate is not limited.
+	limiter *rateLimiter
+	// maxRetries is the number of times a failed request is retried.
+	maxRetries int
+	// minBackoff is the time to wait before the first retry.
+	minBackoff time.Duration
+	// maxBackoff is the maximum time to wait before a retry.
+	maxBackoff time.Duration
+	// maxRetryAfter is the maximum time to wait before a retry when the server asks to wait with the Retry-After header.
+	maxRetryAfter time.Duration
+	// timeout is the maximum duration of a single attempt, 0 if the attempts are not limited.
+	timeout time.Duration
+}
+
+// cancelOnClose is the response body which releases the context of the attempt once it is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	// cancel is the function which releases the context of the attempt.
+	cancel context.CancelFunc
+}
+
+// Close closes the response body and releases the context of the attempt.
+func (body *cancelOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
+
+// RoundTrip sends the request, retrying it when it is rate limited or failed with a transient error.
+// Requests which are not idempotent are only retried when they were rejected because of the rate limit.
+func (transport *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && request.Body != nil {
+			if request.GetBody == nil {
+				return nil, ErrRequestNotReplayable
+			}
+
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			request = request.Clone(request.Context())
+			request.Body = body
+		}
+
+		if err := transport.limiter.wait(request.Context()); err != nil {
+			return nil, err
+		}
+
+		response, err := transport.attempt(request)
+
+		delay, retry := transport.getRetryDelay(request, response, err, attempt)
+		if !retry {
+			return response, err
+		}
+
+		reason := "network error"
+		if err == nil {
+			reason = response.Status
+			_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+			_ = response.Body.Close()
+		}
+
+		log.DebugfWithFields(
+			"request `%s %s` failed (%s), retrying in %s (attempt %d of %d)",
+			log.FieldsMap{
+				"source": "api",
+			},
+			request.Method,
+			request.URL.Path,
+			reason,
+			delay.String(),
+			attempt+1,
+			transport.maxRetries,
+		)
+
+		if err := sleep(request.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends the request once, the attempt is aborted when it takes longer than the timeout.
+// The timeout covers reading the response, so the context of the attempt is released when the response body is closed.
+func (transport *retryTransport) attempt(request *http.Request) (*http.Response, error) {
+	if transport.timeout <= 0 {
+		return transport.base.RoundTrip(request)
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), transport.timeout)
+
+	response, err := transport.base.RoundTrip(request.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	response.Body = &cancelOnClose{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+// getRetryDelay returns the time to wait before the request is retried and a flag that indicates if it should be retried.
+func (transport *retryTransport) getRetryDelay(request *http.Request, response *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= transport.maxRetries || request.Context().Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		return transport.getBackoff(attempt), isIdempotent(request.Method)
+	}
+
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests:
+	case response.StatusCode >= http.StatusInternalServerError && isIdempotent(request.Method):
+	default:
+		return 0, false
+	}
+
+	if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After"), time.Now()); ok {
+		// Waiting longer than the maximum would stall the check, the request is retried on the next one instead.
+		if retryAfter > transport.maxRetryAfter {
+			return 0, false
+		}
+		return retryAfter, true
+	}
+
+	return transport.getBackoff(attempt), true
+}
+
+// getBackoff returns the exponential backoff with jitter for the given attempt.
+func (transport *retryTransport) getBackoff(attempt int) time.Duration {
+	backoff := transport.minBackoff << uint(attempt)
+	if backoff <= 0 || backoff > transport.maxBackoff {
+		backoff = transport.maxBackoff
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// isIdempotent checks if a request with the given method can be safely sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses the value of the Retry-After header, which is either a number of seconds or a date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+
+	return 0, true
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
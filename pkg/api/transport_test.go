@@ -0,0 +1,243 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newRetryingServer(t *testing.T, failures int, status int, retryAfter string) (*httptest.Server, *int) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts++
+
+		if request.Body != nil {
+			body, _ := io.ReadAll(request.Body)
+			if request.Method != http.MethodGet && string(body) != `{"content":"192.0.2.1"}` {
+				t.Errorf("Expected the request body to be replayed but got '%s'", string(body))
+			}
+		}
+
+		if attempts <= failures {
+			if retryAfter != "" {
+				writer.Header().Set("Retry-After", retryAfter)
+			}
+			writer.WriteHeader(status)
+			return
+		}
+
+		_, _ = writer.Write([]byte(`{"success":true}`))
+	}))
+
+	return server, &attempts
+}
+
+func newTestTransport(maxRetries int) *retryTransport {
+	return &retryTransport{
+		base:          http.DefaultTransport,
+		maxRetries:    maxRetries,
+		minBackoff:    time.Millisecond,
+		maxBackoff:    10 * time.Millisecond,
+		maxRetryAfter: time.Minute,
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		failures         int
+		status           int
+		retryAfter       string
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		{
+			name:             "server error is retried for idempotent requests",
+			method:           http.MethodGet,
+			failures:         2,
+			status:           http.StatusServiceUnavailable,
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "server error is not retried for non-idempotent requests",
+			method:           http.MethodPost,
+			failures:         1,
+			status:           http.StatusBadGateway,
+			expectedStatus:   http.StatusBadGateway,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "rate limited request is retried with its body",
+			method:           http.MethodPost,
+			failures:         1,
+			status:           http.StatusTooManyRequests,
+			retryAfter:       "0",
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "retry after beyond the maximum is not waited for",
+			method:           http.MethodPut,
+			failures:         1,
+			status:           http.StatusTooManyRequests,
+			retryAfter:       "300",
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retries are exhausted",
+			method:           http.MethodPut,
+			failures:         5,
+			status:           http.StatusInternalServerError,
+			expectedStatus:   http.StatusInternalServerError,
+			expectedAttempts: 4,
+		},
+		{
+			name:             "client errors are not retried",
+			method:           http.MethodGet,
+			failures:         1,
+			status:           http.StatusBadRequest,
+			expectedStatus:   http.StatusBadRequest,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, attempts := newRetryingServer(t, test.failures, test.status, test.retryAfter)
+			defer server.Close()
+
+			var body io.Reader
+			if test.method != http.MethodGet {
+				body = bytes.NewBufferString(`{"content":"192.0.2.1"}`)
+			}
+
+			request, err := http.NewRequest(test.method, server.URL, body)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+
+			response, err := (&http.Client{Transport: newTestTransport(3)}).Do(request)
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			_ = response.Body.Close()
+
+			if response.StatusCode != test.expectedStatus {
+				t.Errorf("Expected status %d but got %d", test.expectedStatus, response.StatusCode)
+			}
+
+			if *attempts != test.expectedAttempts {
+				t.Errorf("Expected %d attempts but got %d", test.expectedAttempts, *attempts)
+			}
+		})
+	}
+}
+
+func TestRetryDelayHonoursRetryAfterBeyondMaximumBackoff(t *testing.T) {
+	transport := newTestTransport(3)
+
+	request, err := http.NewRequest(http.MethodGet, "https://api.cloudflare.com/client/v4/zones", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	response := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"60"}},
+	}
+
+	delay, retry := transport.getRetryDelay(request, response, nil, 0)
+	if !retry || delay != 60*time.Second {
+		t.Errorf("Expected the request to be retried in %s but got %s (%t)", 60*time.Second, delay, retry)
+	}
+}
+
+func TestRetryTransportLimitsEveryAttempt(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			select {
+			case <-request.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+
+		_, _ = writer.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	transport := newTestTransport(3)
+	transport.timeout = 50 * time.Millisecond
+
+	response, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil || string(body) != `{"success":true}` {
+		t.Errorf("Expected the response of the retried attempt but got '%s' (%v)", string(body), err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected %d attempts but got %d", 2, attempts)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "", expected: 0, ok: false},
+		{value: "30", expected: 30 * time.Second, ok: true},
+		{value: "-1", expected: 0, ok: false},
+		{value: "Thu, 01 Jun 2023 12:00:10 GMT", expected: 10 * time.Second, ok: true},
+		{value: "Thu, 01 Jun 2023 11:59:00 GMT", expected: 0, ok: true},
+		{value: "soon", expected: 0, ok: false},
+	}
+
+	for _, test := range tests {
+		delay, ok := parseRetryAfter(test.value, now)
+		if delay != test.expected || ok != test.ok {
+			t.Errorf("Expected '%s' to be parsed as %s (%t) but got %s (%t)", test.value, test.expected, test.ok, delay, ok)
+		}
+	}
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(4, 2)
+
+	expected := []time.Duration{0, 0, 250 * time.Millisecond, 500 * time.Millisecond}
+	for index, delay := range expected {
+		if reserved := limiter.reserve(now); reserved != delay {
+			t.Errorf("Expected request %d to wait %s but got %s", index+1, delay, reserved)
+		}
+	}
+
+	if reserved := limiter.reserve(now.Add(2 * time.Second)); reserved != 0 {
+		t.Errorf("Expected no wait after the bucket was refilled but got %s", reserved)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	if limiter := newRateLimiter(0, 10); limiter != nil {
+		t.Errorf("Expected no rate limiter but got %+v", limiter)
+	}
+}
@@ -0,0 +1,69 @@
+package address
+
+import "time"
+
+const (
+	// DefaultTimeout is the timeout used when the source does not define one.
+	DefaultTimeout = 10 * time.Second
+	// TypeHTTP is the type of the source which retrieves the address from an HTTP endpoint.
+	TypeHTTP = "http"
+	// TypeInterface is the type of the source which reads the address from a local network interface.
+	TypeInterface = "interface"
+)
+
+// Configuration is the definition of an address source configuration.
+type Configuration struct {
+	// Type is the type of the address source.
+	Type string `json:"type" yaml:"type" xml:"type" toml:"type" mapstructure:"type"`
+	// URL is the URL of the address source.
+	URL string `json:"url" yaml:"url" xml:"url" toml:"url" mapstructure:"url"`
+	// Timeout is the maximum time to wait for the address source to respond.
+	Timeout time.Duration `json:"timeout" yaml:"timeout" xml:"timeout" toml:"timeout" mapstructure:"timeout"`
+	// Interface is the name of the network interface to read the address from.
+	Interface string `json:"interface" yaml:"interface" xml:"interface" toml:"interface" mapstructure:"interface"`
+	// CIDR is the network the address read from the interface has to belong to.
+	CIDR string `json:"cidr" yaml:"cidr" xml:"cidr" toml:"cidr" mapstructure:"cidr"`
+}
+
+// New returns a new address source configuration for the given URL.
+func New(url string) *Configuration {
+	return &Configuration{
+		Type:    TypeHTTP,
+		URL:     url,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// GetType returns the type of the address source.
+func (configuration *Configuration) GetType() string {
+	if configuration.Type == "" {
+		if configuration.Interface != "" && configuration.URL == "" {
+			return TypeInterface
+		}
+		return TypeHTTP
+	}
+	return configuration.Type
+}
+
+// GetURL returns the URL of the address source.
+func (configuration *Configuration) GetURL() string {
+	return configuration.URL
+}
+
+// GetTimeout returns the maximum time to wait for the address source to respond.
+func (configuration *Configuration) GetTimeout() time.Duration {
+	if configuration.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return configuration.Timeout
+}
+
+// GetInterface returns the name of the network interface to read the address from.
+func (configuration *Configuration) GetInterface() string {
+	return configuration.Interface
+}
+
+// GetCIDR returns the network the address read from the interface has to belong to.
+func (configuration *Configuration) GetCIDR() string {
+	return configuration.CIDR
+}
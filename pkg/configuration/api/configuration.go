@@ -0,0 +1,135 @@
+package api
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the base URL of the Cloudflare API.
+	DefaultBaseURL = "https://api.cloudflare.com/client/v4"
+	// MaximumPerPage is the maximum number of results per page accepted by the Cloudflare API.
+	MaximumPerPage = 1000
+)
+
+// Configuration is the definition of the Cloudflare API client configuration.
+type Configuration struct {
+	// BaseURL is the base URL of the Cloudflare API.
+	BaseURL string `json:"base_url" yaml:"base_url" xml:"base_url" toml:"base_url" mapstructure:"base_url" env:"GOFLARESYNC_API_BASE_URL"`
+	// PerPage is the number of results requested per page when listing zones and records.
+	PerPage int `json:"per_page" yaml:"per_page" xml:"per_page" toml:"per_page" mapstructure:"per_page" env:"GOFLARESYNC_API_PER_PAGE"`
+	// Timeout is the maximum duration of a single attempt of a request, including reading the response.
+	Timeout time.Duration `json:"timeout" yaml:"timeout" xml:"timeout" toml:"timeout" mapstructure:"timeout" env:"GOFLARESYNC_API_TIMEOUT"`
+	// MaxRetries is the number of times a failed request is retried.
+	MaxRetries int `json:"max_retries" yaml:"max_retries" xml:"max_retries" toml:"max_retries" mapstructure:"max_retries" env:"GOFLARESYNC_API_MAX_RETRIES"`
+	// RetryMinBackoff is the time to wait before the first retry, it is doubled with every further retry.
+	RetryMinBackoff time.Duration `json:"retry_min_backoff" yaml:"retry_min_backoff" xml:"retry_min_backoff" toml:"retry_min_backoff" mapstructure:"retry_min_backoff" env:"GOFLARESYNC_API_RETRY_MIN_BACKOFF"`
+	// RetryMaxBackoff is the maximum time to wait before a retry.
+	RetryMaxBackoff time.Duration `json:"retry_max_backoff" yaml:"retry_max_backoff" xml:"retry_max_backoff" toml:"retry_max_backoff" mapstructure:"retry_max_backoff" env:"GOFLARESYNC_API_RETRY_MAX_BACKOFF"`
+	// MaxRetryAfter is the maximum time to wait before a retry when the Cloudflare API asks to wait with the Retry-After header.
+	MaxRetryAfter time.Duration `json:"max_retry_after" yaml:"max_retry_after" xml:"max_retry_after" toml:"max_retry_after" mapstructure:"max_retry_after" env:"GOFLARESYNC_API_MAX_RETRY_AFTER"`
+	// RateLimit is the maximum number of requests per second sent to the Cloudflare API, 0 disables the limit.
+	RateLimit float64 `json:"rate_limit" yaml:"rate_limit" xml:"rate_limit" toml:"rate_limit" mapstructure:"rate_limit" env:"GOFLARESYNC_API_RATE_LIMIT"`
+	// RateBurst is the number of requests which can be sent at once before the rate limit applies.
+	RateBurst int `json:"rate_burst" yaml:"rate_burst" xml:"rate_burst" toml:"rate_burst" mapstructure:"rate_burst" env:"GOFLARESYNC_API_RATE_BURST"`
+}
+
+// InitializeWithDefaults initializes the configuration with default values.
+func InitializeWithDefaults() *Configuration {
+	return &Configuration{
+		BaseURL:         DefaultBaseURL,
+		PerPage:         100,
+		Timeout:         30 * time.Second,
+		MaxRetries:      3,
+		RetryMinBackoff: 1 * time.Second,
+		RetryMaxBackoff: 30 * time.Second,
+		MaxRetryAfter:   5 * time.Minute,
+		RateLimit:       4,
+		RateBurst:       10,
+	}
+}
+
+// GetBaseURL returns the base URL of the Cloudflare API without the trailing slash.
+func (configuration *Configuration) GetBaseURL() string {
+	if configuration.BaseURL == "" {
+		return DefaultBaseURL
+	}
+
+	return strings.TrimSuffix(configuration.BaseURL, "/")
+}
+
+// GetPerPage returns the number of results requested per page when listing zones and records.
+func (configuration *Configuration) GetPerPage() int {
+	if configuration.PerPage < 1 {
+		return InitializeWithDefaults().PerPage
+	}
+
+	if configuration.PerPage > MaximumPerPage {
+		return MaximumPerPage
+	}
+
+	return configuration.PerPage
+}
+
+// GetTimeout returns the maximum duration of a single attempt of a request.
+func (configuration *Configuration) GetTimeout() time.Duration {
+	if configuration.Timeout <= 0 {
+		return InitializeWithDefaults().Timeout
+	}
+
+	return configuration.Timeout
+}
+
+// GetMaxRetries returns the number of times a failed request is retried.
+func (configuration *Configuration) GetMaxRetries() int {
+	if configuration.MaxRetries < 0 {
+		return 0
+	}
+
+	return configuration.MaxRetries
+}
+
+// GetRetryMinBackoff returns the time to wait before the first retry.
+func (configuration *Configuration) GetRetryMinBackoff() time.Duration {
+	if configuration.RetryMinBackoff <= 0 {
+		return InitializeWithDefaults().RetryMinBackoff
+	}
+
+	return configuration.RetryMinBackoff
+}
+
+// GetRetryMaxBackoff returns the maximum time to wait before a retry.
+func (configuration *Configuration) GetRetryMaxBackoff() time.Duration {
+	if configuration.RetryMaxBackoff < configuration.GetRetryMinBackoff() {
+		return configuration.GetRetryMinBackoff()
+	}
+
+	return configuration.RetryMaxBackoff
+}
+
+// GetMaxRetryAfter returns the maximum time to wait before a retry when the Cloudflare API asks to wait.
+func (configuration *Configuration) GetMaxRetryAfter() time.Duration {
+	if configuration.MaxRetryAfter <= 0 {
+		return InitializeWithDefaults().MaxRetryAfter
+	}
+
+	return configuration.MaxRetryAfter
+}
+
+// GetRateLimit returns the maximum number of requests per second, 0 means there is no limit.
+func (configuration *Configuration) GetRateLimit() float64 {
+	if configuration.RateLimit < 0 {
+		return 0
+	}
+
+	return configuration.RateLimit
+}
+
+// GetRateBurst returns the number of requests which can be sent at once before the rate limit applies.
+func (configuration *Configuration) GetRateBurst() int {
+	if configuration.RateBurst < 1 {
+		return 1
+	}
+
+	return configuration.RateBurst
+}
@@ -1,19 +1,48 @@
 package cloudflare
 
+import "strings"
+
+const (
+	// AuthModeToken is the authentication mode which uses an API token.
+	AuthModeToken = "token"
+	// AuthModeKey is the authentication mode which uses the E-Mail and the Global API Key.
+	AuthModeKey = "key"
+)
+
 // Configuration is the Cloudflare configuration.
 type Configuration struct {
+	// AuthMode is the authentication mode, either `token` or `key`.
+	AuthMode string `json:"auth_mode" yaml:"auth_mode" xml:"auth_mode" toml:"auth_mode" mapstructure:"auth_mode"`
 	// Email is the email of the Cloudflare account.
 	Email string `json:"email" yaml:"email" xml:"email" toml:"email" mapstructure:"email"`
 	// Token is the token of the Cloudflare account.
 	Token string `json:"token" yaml:"token" xml:"token" toml:"token" mapstructure:"token"`
+	// Key is the Global API Key of the Cloudflare account.
+	Key string `json:"key" yaml:"key" xml:"key" toml:"key" mapstructure:"key"`
 }
 
 // InitializeWithDefaults initializes the configuration with defaults.
 func InitializeWithDefaults() *Configuration {
 	return &Configuration{
-		Email: "",
-		Token: "",
+		AuthMode: "",
+		Email:    "",
+		Token:    "",
+		Key:      "",
+	}
+}
+
+// GetAuthMode returns the authentication mode.
+// When no mode is configured, the key mode is used if only the Global API Key is set and the token mode otherwise.
+func (configuration *Configuration) GetAuthMode() string {
+	if configuration.AuthMode != "" {
+		return strings.ToLower(configuration.AuthMode)
 	}
+
+	if configuration.Key != "" && configuration.Token == "" {
+		return AuthModeKey
+	}
+
+	return AuthModeToken
 }
 
 // GetEmail returns the email of the Cloudflare account.
@@ -25,3 +54,8 @@ func (configuration *Configuration) GetEmail() string {
 func (configuration *Configuration) GetToken() string {
 	return configuration.Token
 }
+
+// GetKey returns the Global API Key of the Cloudflare account.
+func (configuration *Configuration) GetKey() string {
+	return configuration.Key
+}
@@ -1,8 +1,12 @@
 package configuration
 
 import (
+	"github.com/darki73/goflaresync/pkg/configuration/api"
 	"github.com/darki73/goflaresync/pkg/configuration/cloudflare"
+	"github.com/darki73/goflaresync/pkg/configuration/dyndns"
+	"github.com/darki73/goflaresync/pkg/configuration/provider"
 	"github.com/darki73/goflaresync/pkg/configuration/records"
+	"github.com/darki73/goflaresync/pkg/configuration/transport"
 	"github.com/darki73/goflaresync/pkg/configuration/watcher"
 	"github.com/darki73/goflaresync/pkg/log"
 	"github.com/fsnotify/fsnotify"
@@ -20,10 +24,18 @@ var (
 type Configuration struct {
 	// Credentials is the Cloudflare Credentials configuration.
 	Credentials *cloudflare.Configuration `json:"credentials" yaml:"credentials" xml:"credentials" toml:"credentials" mapstructure:"credentials"`
+	// Providers is the list of DNS provider instances.
+	Providers []*provider.Configuration `json:"providers" yaml:"providers" xml:"providers" toml:"providers" mapstructure:"providers"`
 	// Records is the Cloudflare Records configuration.
 	Records []*records.Configuration `json:"records" yaml:"records" xml:"records" toml:"records" mapstructure:"records"`
 	// Watcher is the Watcher configuration.
 	Watcher *watcher.Configuration `json:"watcher" yaml:"watcher" xml:"watcher" toml:"watcher" mapstructure:"watcher"`
+	// API is the Cloudflare API client configuration.
+	API *api.Configuration `json:"api" yaml:"api" xml:"api" toml:"api" mapstructure:"api"`
+	// HTTP is the configuration of the outgoing HTTP requests.
+	HTTP *transport.Configuration `json:"http" yaml:"http" xml:"http" toml:"http" mapstructure:"http"`
+	// DynDNS is the configuration of the dyndns2 compatible update server.
+	DynDNS *dyndns.Configuration `json:"dyndns" yaml:"dyndns" xml:"dyndns" toml:"dyndns" mapstructure:"dyndns"`
 	// LogLevel is the log level.
 	LogLevel string `json:"log_level" yaml:"log_level" xml:"log_level" toml:"log_level" mapstructure:"log_level" env:"GOFLARESYNC_LOG_LEVEL"`
 }
@@ -33,6 +45,20 @@ func (configuration *Configuration) GetCredentials() *cloudflare.Configuration {
 	return configuration.Credentials
 }
 
+// GetProviders returns the list of DNS provider instances.
+// The default Cloudflare provider, which uses the global credentials, is included unless it is declared explicitly.
+func (configuration *Configuration) GetProviders() []*provider.Configuration {
+	providers := append([]*provider.Configuration{}, configuration.Providers...)
+
+	for _, declared := range providers {
+		if declared.GetName() == provider.DefaultName {
+			return providers
+		}
+	}
+
+	return append(providers, provider.New(provider.DefaultName, provider.TypeCloudflare))
+}
+
 // GetRecords returns the Cloudflare Records configuration.
 func (configuration *Configuration) GetRecords() []*records.Configuration {
 	return configuration.Records
@@ -43,12 +69,41 @@ func (configuration *Configuration) GetWatcher() *watcher.Configuration {
 	return configuration.Watcher
 }
 
+// GetAPI returns the Cloudflare API client configuration.
+func (configuration *Configuration) GetAPI() *api.Configuration {
+	return configuration.API
+}
+
+// GetHTTP returns the configuration of the outgoing HTTP requests.
+func (configuration *Configuration) GetHTTP() *transport.Configuration {
+	return configuration.HTTP
+}
+
+// GetDynDNS returns the configuration of the dyndns2 compatible update server.
+func (configuration *Configuration) GetDynDNS() *dyndns.Configuration {
+	return configuration.DynDNS
+}
+
 // GetLogLevel returns the log level.
 func (configuration *Configuration) GetLogLevel() log.Level {
 	logLevel, _ := log.ParseLevel(configuration.LogLevel)
 	return logLevel
 }
 
+// InitializeWithDefaults initializes the configuration with default values.
+func InitializeWithDefaults() *Configuration {
+	return &Configuration{
+		Credentials: cloudflare.InitializeWithDefaults(),
+		Providers:   []*provider.Configuration{},
+		Records:     []*records.Configuration{},
+		Watcher:     watcher.InitializeWithDefaults(),
+		API:         api.InitializeWithDefaults(),
+		HTTP:        transport.InitializeWithDefaults(),
+		DynDNS:      dyndns.InitializeWithDefaults(),
+		LogLevel:    "i",
+	}
+}
+
 // LoadConfiguration loads the configuration from the given options.
 func LoadConfiguration(options *ConfigurationOptions) error {
 	viper.SetConfigName(options.GetName())
@@ -60,12 +115,7 @@ func LoadConfiguration(options *ConfigurationOptions) error {
 		return err
 	}
 
-	configuration = &Configuration{
-		Credentials: cloudflare.InitializeWithDefaults(),
-		Records:     []*records.Configuration{},
-		Watcher:     watcher.InitializeWithDefaults(),
-		LogLevel:    "i",
-	}
+	configuration = InitializeWithDefaults()
 
 	if err := viper.Unmarshal(configuration); err != nil {
 		return err
@@ -85,35 +135,79 @@ func LoadConfiguration(options *ConfigurationOptions) error {
 			event.Name,
 		)
 
-		mutex.Lock()
-		defer mutex.Unlock()
-
-		if err := viper.Unmarshal(configuration); err != nil {
-			log.ErrorfWithFields(
-				"error re-loading configuration: %s",
-				log.FieldsMap{
-					"source": "configuration",
-				},
-				err,
-			)
-		}
-
-		if err := setLogLevel(); err != nil {
-			log.ErrorfWithFields(
-				"error setting log level: %s",
-				log.FieldsMap{
-					"source": "configuration",
-				},
-				err,
-			)
-		}
+		applyChangedConfiguration()
 
+		// The lock is released before the change is announced, the receiver reads the configuration while handling it.
 		ChangeChannel <- true
 	})
 
 	return nil
 }
 
+// applyChangedConfiguration replaces the configuration with the one read from the changed file.
+// The configuration is swapped rather than modified in place, since it is read while the file is decoded.
+func applyChangedConfiguration() {
+	changedConfiguration := InitializeWithDefaults()
+	if err := viper.Unmarshal(changedConfiguration); err != nil {
+		log.ErrorfWithFields(
+			"error re-loading configuration: %s",
+			log.FieldsMap{
+				"source": "configuration",
+			},
+			err,
+		)
+		return
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	configuration = changedConfiguration
+
+	if err := setLogLevel(); err != nil {
+		log.ErrorfWithFields(
+			"error setting log level: %s",
+			log.FieldsMap{
+				"source": "configuration",
+			},
+			err,
+		)
+	}
+}
+
+// ReloadConfiguration reads the configuration file again without waiting for it to change.
+// The current configuration is kept when the file can not be read.
+// The file is read by a separate reader, since the global one is also used when the file changes.
+func ReloadConfiguration() error {
+	reader := viper.New()
+	reader.SetConfigFile(viper.ConfigFileUsed())
+	reader.AutomaticEnv()
+
+	if err := reader.ReadInConfig(); err != nil {
+		return err
+	}
+
+	reloadedConfiguration := InitializeWithDefaults()
+	if err := reader.Unmarshal(reloadedConfiguration); err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	configuration = reloadedConfiguration
+
+	return setLogLevel()
+}
+
+// SetConfiguration replaces the configuration of the application, it is meant to be used by tests.
+func SetConfiguration(newConfiguration *Configuration) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	configuration = newConfiguration
+}
+
 // GetConfiguration returns the configuration for the application.
 func GetConfiguration() *Configuration {
 	mutex.RLock()
@@ -0,0 +1,112 @@
+package dyndns
+
+import "strings"
+
+const (
+	// DefaultListen is the address the update server listens on when none is configured.
+	DefaultListen = ":8245"
+)
+
+// Configuration is the definition of the configuration of the dyndns2 compatible update server.
+type Configuration struct {
+	// Listen is the address the update server listens on.
+	Listen string `json:"listen" yaml:"listen" xml:"listen" toml:"listen" mapstructure:"listen" env:"GOFLARESYNC_DYNDNS_LISTEN"`
+	// TLSCertificate is the path to the PEM encoded certificate, the server uses plain HTTP when it is empty.
+	TLSCertificate string `json:"tls_certificate" yaml:"tls_certificate" xml:"tls_certificate" toml:"tls_certificate" mapstructure:"tls_certificate" env:"GOFLARESYNC_DYNDNS_TLS_CERTIFICATE"`
+	// TLSKey is the path to the PEM encoded private key of the certificate.
+	TLSKey string `json:"tls_key" yaml:"tls_key" xml:"tls_key" toml:"tls_key" mapstructure:"tls_key" env:"GOFLARESYNC_DYNDNS_TLS_KEY"`
+	// Users is the list of users allowed to push updates.
+	Users []*User `json:"users" yaml:"users" xml:"users" toml:"users" mapstructure:"users"`
+}
+
+// User is the definition of a user allowed to push updates.
+type User struct {
+	// Username is the name the user authenticates with.
+	Username string `json:"username" yaml:"username" xml:"username" toml:"username" mapstructure:"username"`
+	// Password is the password the user authenticates with.
+	Password string `json:"password" yaml:"password" xml:"password" toml:"password" mapstructure:"password"`
+	// Hostnames is the list of hostnames the user is allowed to update.
+	Hostnames []string `json:"hostnames" yaml:"hostnames" xml:"hostnames" toml:"hostnames" mapstructure:"hostnames"`
+}
+
+// InitializeWithDefaults initializes the configuration with default values.
+func InitializeWithDefaults() *Configuration {
+	return &Configuration{
+		Listen:         DefaultListen,
+		TLSCertificate: "",
+		TLSKey:         "",
+		Users:          []*User{},
+	}
+}
+
+// GetListen returns the address the update server listens on.
+func (configuration *Configuration) GetListen() string {
+	if configuration.Listen == "" {
+		return DefaultListen
+	}
+
+	return configuration.Listen
+}
+
+// GetTLSCertificate returns the path to the PEM encoded certificate.
+func (configuration *Configuration) GetTLSCertificate() string {
+	return configuration.TLSCertificate
+}
+
+// GetTLSKey returns the path to the PEM encoded private key of the certificate, the certificate path is used when it is empty.
+func (configuration *Configuration) GetTLSKey() string {
+	if configuration.TLSKey == "" {
+		return configuration.TLSCertificate
+	}
+
+	return configuration.TLSKey
+}
+
+// GetUsers returns the list of users allowed to push updates.
+func (configuration *Configuration) GetUsers() []*User {
+	return configuration.Users
+}
+
+// GetUser returns the user with the given name, or nil if there is none.
+func (configuration *Configuration) GetUser(username string) *User {
+	for _, user := range configuration.Users {
+		if user.GetUsername() == username {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// GetUsername returns the name the user authenticates with.
+func (user *User) GetUsername() string {
+	return user.Username
+}
+
+// GetPassword returns the password the user authenticates with.
+func (user *User) GetPassword() string {
+	return user.Password
+}
+
+// GetHostnames returns the list of hostnames the user is allowed to update.
+func (user *User) GetHostnames() []string {
+	return user.Hostnames
+}
+
+// IsAllowed checks if the user is allowed to update the given hostname.
+func (user *User) IsAllowed(hostname string) bool {
+	hostname = normalizeHostname(hostname)
+
+	for _, allowed := range user.Hostnames {
+		if normalizeHostname(allowed) == hostname {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeHostname returns the hostname in lower case and without the trailing dot.
+func normalizeHostname(hostname string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"github.com/darki73/goflaresync/pkg/configuration/cloudflare"
+	"github.com/darki73/goflaresync/pkg/configuration/rfc2136"
+	"strings"
+)
+
+const (
+	// DefaultName is the name of the provider used by the records which do not name one.
+	DefaultName = "cloudflare"
+	// TypeCloudflare is the type of the provider which manages the records through the Cloudflare API.
+	TypeCloudflare = "cloudflare"
+	// TypeRFC2136 is the type of the provider which manages the records with RFC 2136 dynamic DNS updates.
+	TypeRFC2136 = "rfc2136"
+)
+
+// Configuration is the definition of a DNS provider instance configuration.
+type Configuration struct {
+	// Name is the name of the provider instance the records refer to.
+	Name string `json:"name" yaml:"name" xml:"name" toml:"name" mapstructure:"name"`
+	// Type is the type of the provider.
+	Type string `json:"type" yaml:"type" xml:"type" toml:"type" mapstructure:"type"`
+	// Credentials is the Cloudflare credentials of the provider instance, the global credentials are used when they are not set.
+	Credentials *cloudflare.Configuration `json:"credentials" yaml:"credentials" xml:"credentials" toml:"credentials" mapstructure:"credentials"`
+	// RFC2136 is the configuration of the RFC 2136 provider instance.
+	RFC2136 *rfc2136.Configuration `json:"rfc2136" yaml:"rfc2136" xml:"rfc2136" toml:"rfc2136" mapstructure:"rfc2136"`
+}
+
+// New returns a new provider configuration with the given name and type.
+func New(name string, providerType string) *Configuration {
+	return &Configuration{
+		Name: name,
+		Type: providerType,
+	}
+}
+
+// GetName returns the name of the provider instance.
+func (configuration *Configuration) GetName() string {
+	return configuration.Name
+}
+
+// GetType returns the type of the provider, Cloudflare is used when no type is configured.
+func (configuration *Configuration) GetType() string {
+	if configuration.Type == "" {
+		return TypeCloudflare
+	}
+
+	return strings.ToLower(configuration.Type)
+}
+
+// GetCredentials returns the Cloudflare credentials of the provider instance, or nil if they are not set.
+func (configuration *Configuration) GetCredentials() *cloudflare.Configuration {
+	return configuration.Credentials
+}
+
+// GetRFC2136 returns the configuration of the RFC 2136 provider instance, or nil if it is not set.
+func (configuration *Configuration) GetRFC2136() *rfc2136.Configuration {
+	return configuration.RFC2136
+}
@@ -1,11 +1,32 @@
 package records
 
+import "github.com/darki73/goflaresync/pkg/configuration/provider"
+
+const (
+	// DefaultTTL is the TTL of the created records when none is configured (1 means automatic).
+	DefaultTTL = 1
+)
+
 // Configuration is the definition of a record configuration.
 type Configuration struct {
 	// Type is the type of the record.
 	Type string `json:"type" yaml:"type" xml:"type" toml:"type" mapstructure:"type"`
 	// Name is the name of the record.
 	Name string `json:"name" yaml:"name" xml:"name" toml:"name" mapstructure:"name"`
+	// Provider is the name of the provider instance the record belongs to.
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty" xml:"provider,omitempty" toml:"provider,omitempty" mapstructure:"provider"`
+	// AllowPrivateAddress is a flag that indicates if private, loopback and other non-public addresses can be published.
+	AllowPrivateAddress bool `json:"allow_private_address" yaml:"allow_private_address" xml:"allow_private_address" toml:"allow_private_address" mapstructure:"allow_private_address"`
+	// CreateIfMissing is a flag that indicates if the record should be created when it does not exist.
+	CreateIfMissing bool `json:"create_if_missing" yaml:"create_if_missing" xml:"create_if_missing" toml:"create_if_missing" mapstructure:"create_if_missing"`
+	// Proxied is a flag that indicates if the record is proxied.
+	Proxied *bool `json:"proxied,omitempty" yaml:"proxied,omitempty" xml:"proxied,omitempty" toml:"proxied,omitempty" mapstructure:"proxied"`
+	// TTL is the TTL of the record.
+	TTL int `json:"ttl,omitempty" yaml:"ttl,omitempty" xml:"ttl,omitempty" toml:"ttl,omitempty" mapstructure:"ttl"`
+	// Comment is the comment of the record.
+	Comment *string `json:"comment,omitempty" yaml:"comment,omitempty" xml:"comment,omitempty" toml:"comment,omitempty" mapstructure:"comment"`
+	// Tags is the list of tags of the record.
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty" xml:"tags,omitempty" toml:"tags,omitempty" mapstructure:"tags"`
 }
 
 // GetType returns the type of the record.
@@ -17,3 +38,68 @@ func (configuration *Configuration) GetType() string {
 func (configuration *Configuration) GetName() string {
 	return configuration.Name
 }
+
+// GetProvider returns the name of the provider instance the record belongs to.
+func (configuration *Configuration) GetProvider() string {
+	if configuration.Provider == "" {
+		return provider.DefaultName
+	}
+
+	return configuration.Provider
+}
+
+// GetAllowPrivateAddress returns a flag that indicates if private, loopback and other non-public addresses can be published.
+func (configuration *Configuration) GetAllowPrivateAddress() bool {
+	return configuration.AllowPrivateAddress
+}
+
+// GetCreateIfMissing returns a flag that indicates if the record should be created when it does not exist.
+func (configuration *Configuration) GetCreateIfMissing() bool {
+	return configuration.CreateIfMissing
+}
+
+// HasProxied checks if the proxied flag is configured.
+func (configuration *Configuration) HasProxied() bool {
+	return configuration.Proxied != nil
+}
+
+// GetProxied returns a flag that indicates if the record is proxied.
+func (configuration *Configuration) GetProxied() bool {
+	return configuration.Proxied != nil && *configuration.Proxied
+}
+
+// HasTTL checks if the TTL is configured.
+func (configuration *Configuration) HasTTL() bool {
+	return configuration.TTL > 0
+}
+
+// GetTTL returns the TTL of the record.
+func (configuration *Configuration) GetTTL() int {
+	if !configuration.HasTTL() {
+		return DefaultTTL
+	}
+	return configuration.TTL
+}
+
+// HasComment checks if the comment is configured.
+func (configuration *Configuration) HasComment() bool {
+	return configuration.Comment != nil
+}
+
+// GetComment returns the comment of the record.
+func (configuration *Configuration) GetComment() string {
+	if configuration.Comment == nil {
+		return ""
+	}
+	return *configuration.Comment
+}
+
+// HasTags checks if the tags are configured.
+func (configuration *Configuration) HasTags() bool {
+	return configuration.Tags != nil
+}
+
+// GetTags returns the list of tags of the record.
+func (configuration *Configuration) GetTags() []string {
+	return configuration.Tags
+}
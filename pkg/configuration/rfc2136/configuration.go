@@ -0,0 +1,122 @@
+package rfc2136
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPort is the port of the DNS server used when the server address does not contain one.
+	DefaultPort = "53"
+	// ProtocolUDP is the protocol which sends the messages over UDP and falls back to TCP for truncated responses.
+	ProtocolUDP = "udp"
+	// ProtocolTCP is the protocol which sends the messages over TCP.
+	ProtocolTCP = "tcp"
+	// DefaultTSIGAlgorithm is the algorithm used to sign the messages when none is configured.
+	DefaultTSIGAlgorithm = "hmac-sha256"
+	// DefaultTTL is the TTL of the records created without an explicit TTL.
+	DefaultTTL = 300
+)
+
+// Configuration is the definition of the RFC 2136 dynamic DNS update provider configuration.
+type Configuration struct {
+	// Server is the address of the authoritative DNS server which accepts the updates.
+	Server string `json:"server" yaml:"server" xml:"server" toml:"server" mapstructure:"server"`
+	// Protocol is the protocol used to send the messages to the server.
+	Protocol string `json:"protocol" yaml:"protocol" xml:"protocol" toml:"protocol" mapstructure:"protocol"`
+	// Zones is the list of zones served by the server, the zones are discovered with SOA queries when it is empty.
+	Zones []string `json:"zones" yaml:"zones" xml:"zones" toml:"zones" mapstructure:"zones"`
+	// TSIGKeyName is the name of the TSIG key used to sign the messages.
+	TSIGKeyName string `json:"tsig_key_name" yaml:"tsig_key_name" xml:"tsig_key_name" toml:"tsig_key_name" mapstructure:"tsig_key_name"`
+	// TSIGSecret is the base64 encoded secret of the TSIG key.
+	TSIGSecret string `json:"tsig_secret" yaml:"tsig_secret" xml:"tsig_secret" toml:"tsig_secret" mapstructure:"tsig_secret"`
+	// TSIGAlgorithm is the algorithm of the TSIG key.
+	TSIGAlgorithm string `json:"tsig_algorithm" yaml:"tsig_algorithm" xml:"tsig_algorithm" toml:"tsig_algorithm" mapstructure:"tsig_algorithm"`
+	// Timeout is the maximum duration of a single exchange with the server.
+	Timeout time.Duration `json:"timeout" yaml:"timeout" xml:"timeout" toml:"timeout" mapstructure:"timeout"`
+	// TTL is the TTL of the records created or updated without an explicit TTL.
+	TTL int `json:"ttl" yaml:"ttl" xml:"ttl" toml:"ttl" mapstructure:"ttl"`
+}
+
+// InitializeWithDefaults initializes the configuration with default values.
+func InitializeWithDefaults() *Configuration {
+	return &Configuration{
+		Server:        "",
+		Protocol:      ProtocolUDP,
+		Zones:         []string{},
+		TSIGKeyName:   "",
+		TSIGSecret:    "",
+		TSIGAlgorithm: DefaultTSIGAlgorithm,
+		Timeout:       10 * time.Second,
+		TTL:           DefaultTTL,
+	}
+}
+
+// GetServer returns the address of the DNS server, including the port.
+func (configuration *Configuration) GetServer() string {
+	if configuration.Server == "" {
+		return ""
+	}
+
+	if _, _, err := net.SplitHostPort(configuration.Server); err == nil {
+		return configuration.Server
+	}
+
+	return net.JoinHostPort(strings.Trim(configuration.Server, "[]"), DefaultPort)
+}
+
+// GetProtocol returns the protocol used to send the messages, UDP is used when no protocol is configured.
+func (configuration *Configuration) GetProtocol() string {
+	if strings.ToLower(configuration.Protocol) == ProtocolTCP {
+		return ProtocolTCP
+	}
+
+	return ProtocolUDP
+}
+
+// GetZones returns the list of zones served by the server in lower case and without the trailing dot.
+func (configuration *Configuration) GetZones() []string {
+	zones := make([]string, 0, len(configuration.Zones))
+	for _, zone := range configuration.Zones {
+		zones = append(zones, strings.TrimSuffix(strings.ToLower(zone), "."))
+	}
+	return zones
+}
+
+// GetTSIGKeyName returns the name of the TSIG key.
+func (configuration *Configuration) GetTSIGKeyName() string {
+	return configuration.TSIGKeyName
+}
+
+// GetTSIGSecret returns the base64 encoded secret of the TSIG key.
+func (configuration *Configuration) GetTSIGSecret() string {
+	return configuration.TSIGSecret
+}
+
+// GetTSIGAlgorithm returns the algorithm of the TSIG key.
+func (configuration *Configuration) GetTSIGAlgorithm() string {
+	if configuration.TSIGAlgorithm == "" {
+		return DefaultTSIGAlgorithm
+	}
+
+	return strings.ToLower(configuration.TSIGAlgorithm)
+}
+
+// GetTimeout returns the maximum duration of a single exchange with the server.
+func (configuration *Configuration) GetTimeout() time.Duration {
+	if configuration.Timeout <= 0 {
+		return 10 * time.Second
+	}
+
+	return configuration.Timeout
+}
+
+// GetTTL returns the TTL of the records created or updated without an explicit TTL.
+func (configuration *Configuration) GetTTL() int {
+	if configuration.TTL < 1 {
+		return DefaultTTL
+	}
+
+	return configuration.TTL
+}
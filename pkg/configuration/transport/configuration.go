@@ -0,0 +1,48 @@
+package transport
+
+// Configuration is the definition of the configuration of the outgoing HTTP requests.
+type Configuration struct {
+	// Proxy is the URL of the HTTP(S) proxy, the proxy environment variables are used when it is empty.
+	Proxy string `json:"proxy" yaml:"proxy" xml:"proxy" toml:"proxy" mapstructure:"proxy" env:"GOFLARESYNC_HTTP_PROXY"`
+	// CAFile is the path to the PEM bundle of the certificate authorities trusted in addition to the system ones.
+	CAFile string `json:"ca_file" yaml:"ca_file" xml:"ca_file" toml:"ca_file" mapstructure:"ca_file" env:"GOFLARESYNC_HTTP_CA_FILE"`
+	// ClientCertificate is the path to the PEM encoded client certificate.
+	ClientCertificate string `json:"client_certificate" yaml:"client_certificate" xml:"client_certificate" toml:"client_certificate" mapstructure:"client_certificate" env:"GOFLARESYNC_HTTP_CLIENT_CERTIFICATE"`
+	// ClientKey is the path to the PEM encoded private key of the client certificate.
+	ClientKey string `json:"client_key" yaml:"client_key" xml:"client_key" toml:"client_key" mapstructure:"client_key" env:"GOFLARESYNC_HTTP_CLIENT_KEY"`
+}
+
+// InitializeWithDefaults initializes the configuration with default values.
+func InitializeWithDefaults() *Configuration {
+	return &Configuration{
+		Proxy:             "",
+		CAFile:            "",
+		ClientCertificate: "",
+		ClientKey:         "",
+	}
+}
+
+// GetProxy returns the URL of the HTTP(S) proxy.
+func (configuration *Configuration) GetProxy() string {
+	return configuration.Proxy
+}
+
+// GetCAFile returns the path to the PEM bundle of the additionally trusted certificate authorities.
+func (configuration *Configuration) GetCAFile() string {
+	return configuration.CAFile
+}
+
+// GetClientCertificate returns the path to the PEM encoded client certificate.
+func (configuration *Configuration) GetClientCertificate() string {
+	return configuration.ClientCertificate
+}
+
+// GetClientKey returns the path to the PEM encoded private key of the client certificate.
+// When no key is configured, the key is expected to be bundled with the client certificate.
+func (configuration *Configuration) GetClientKey() string {
+	if configuration.ClientKey == "" {
+		return configuration.ClientCertificate
+	}
+
+	return configuration.ClientKey
+}
@@ -1,20 +1,53 @@
 package watcher
 
-import "time"
+import (
+	"github.com/darki73/goflaresync/pkg/configuration/address"
+	"time"
+)
 
 // Configuration is the definition of the configuration of the watcher.
 type Configuration struct {
 	// Interval is the interval at which the watcher will check for changes.
 	Interval time.Duration `json:"interval" yaml:"interval" xml:"interval" toml:"interval" mapstructure:"interval" env:"GOFLARESYNC_WATCHER_INTERVAL"`
-	// AddressSource is the source of real ip address.
+	// AddressSource is the source of real IPv4 address.
 	AddressSource string `json:"address_source" yaml:"address_source" xml:"address_source" toml:"address_source" mapstructure:"address_source" env:"GOFLARESYNC_ADDRESS_SOURCE"`
+	// AddressSourceIPv6 is the source of real IPv6 address.
+	AddressSourceIPv6 string `json:"address_source_ipv6" yaml:"address_source_ipv6" xml:"address_source_ipv6" toml:"address_source_ipv6" mapstructure:"address_source_ipv6" env:"GOFLARESYNC_ADDRESS_SOURCE_IPV6"`
+	// AddressSources is the ordered list of sources of real IPv4 address.
+	AddressSources []*address.Configuration `json:"address_sources" yaml:"address_sources" xml:"address_sources" toml:"address_sources" mapstructure:"address_sources"`
+	// AddressSourcesIPv6 is the ordered list of sources of real IPv6 address.
+	AddressSourcesIPv6 []*address.Configuration `json:"address_sources_ipv6" yaml:"address_sources_ipv6" xml:"address_sources_ipv6" toml:"address_sources_ipv6" mapstructure:"address_sources_ipv6"`
+	// AddressStrategy is the strategy used to pick the address out of the address sources.
+	AddressStrategy string `json:"address_strategy" yaml:"address_strategy" xml:"address_strategy" toml:"address_strategy" mapstructure:"address_strategy" env:"GOFLARESYNC_ADDRESS_STRATEGY"`
+	// AddressQuorum is the number of address sources which have to agree when the majority strategy is used.
+	AddressQuorum int `json:"address_quorum" yaml:"address_quorum" xml:"address_quorum" toml:"address_quorum" mapstructure:"address_quorum" env:"GOFLARESYNC_ADDRESS_QUORUM"`
+	// Events is a flag that indicates if the watcher should react to local address changes (Linux only).
+	Events bool `json:"events" yaml:"events" xml:"events" toml:"events" mapstructure:"events" env:"GOFLARESYNC_WATCHER_EVENTS"`
+	// EventsDebounce is the time to wait for further local address changes before the records are updated.
+	EventsDebounce time.Duration `json:"events_debounce" yaml:"events_debounce" xml:"events_debounce" toml:"events_debounce" mapstructure:"events_debounce" env:"GOFLARESYNC_WATCHER_EVENTS_DEBOUNCE"`
+	// StateFile is the path to the file used to persist the state between the synchronizations.
+	StateFile string `json:"state_file" yaml:"state_file" xml:"state_file" toml:"state_file" mapstructure:"state_file" env:"GOFLARESYNC_WATCHER_STATE_FILE"`
+	// ReconciliationInterval is the interval at which all records are looked up even if the address has not changed.
+	ReconciliationInterval time.Duration `json:"reconciliation_interval" yaml:"reconciliation_interval" xml:"reconciliation_interval" toml:"reconciliation_interval" mapstructure:"reconciliation_interval" env:"GOFLARESYNC_WATCHER_RECONCILIATION_INTERVAL"`
+	// ShutdownTimeout is the maximum time to wait for the in-flight update to finish when the watcher is stopped.
+	ShutdownTimeout time.Duration `json:"shutdown_timeout" yaml:"shutdown_timeout" xml:"shutdown_timeout" toml:"shutdown_timeout" mapstructure:"shutdown_timeout" env:"GOFLARESYNC_WATCHER_SHUTDOWN_TIMEOUT"`
 }
 
 // InitializeWithDefaults initializes the configuration with default values.
 func InitializeWithDefaults() *Configuration {
 	return &Configuration{
-		Interval:      5 * time.Minute,
-		AddressSource: "https://api.ipify.org",
+		Interval:               5 * time.Minute,
+		AddressSource:          "https://api.ipify.org",
+		AddressSourceIPv6:      "https://api6.ipify.org",
+		AddressSources:         []*address.Configuration{},
+		AddressSourcesIPv6:     []*address.Configuration{},
+		AddressStrategy:        "first-success",
+		AddressQuorum:          0,
+		Events:                 true,
+		EventsDebounce:         5 * time.Second,
+		StateFile:              "/var/lib/goflaresync/state.json",
+		ReconciliationInterval: 1 * time.Hour,
+		ShutdownTimeout:        30 * time.Second,
 	}
 }
 
@@ -23,7 +56,75 @@ func (configuration *Configuration) GetInterval() time.Duration {
 	return configuration.Interval
 }
 
-// GetAddressSource returns the source of real ip address.
+// GetAddressSource returns the source of real IPv4 address.
 func (configuration *Configuration) GetAddressSource() string {
 	return configuration.AddressSource
 }
+
+// GetAddressSourceIPv6 returns the source of real IPv6 address.
+func (configuration *Configuration) GetAddressSourceIPv6() string {
+	return configuration.AddressSourceIPv6
+}
+
+// GetAddressSources returns the ordered list of sources of real IPv4 address.
+// When no list is configured, the single address source is used instead.
+func (configuration *Configuration) GetAddressSources() []*address.Configuration {
+	if len(configuration.AddressSources) > 0 {
+		return configuration.AddressSources
+	}
+
+	if configuration.GetAddressSource() == "" {
+		return []*address.Configuration{}
+	}
+
+	return []*address.Configuration{address.New(configuration.GetAddressSource())}
+}
+
+// GetAddressSourcesIPv6 returns the ordered list of sources of real IPv6 address.
+// When no list is configured, the single address source is used instead.
+func (configuration *Configuration) GetAddressSourcesIPv6() []*address.Configuration {
+	if len(configuration.AddressSourcesIPv6) > 0 {
+		return configuration.AddressSourcesIPv6
+	}
+
+	if configuration.GetAddressSourceIPv6() == "" {
+		return []*address.Configuration{}
+	}
+
+	return []*address.Configuration{address.New(configuration.GetAddressSourceIPv6())}
+}
+
+// GetAddressStrategy returns the strategy used to pick the address out of the address sources.
+func (configuration *Configuration) GetAddressStrategy() string {
+	return configuration.AddressStrategy
+}
+
+// GetAddressQuorum returns the number of address sources which have to agree when the majority strategy is used.
+func (configuration *Configuration) GetAddressQuorum() int {
+	return configuration.AddressQuorum
+}
+
+// GetEvents returns a flag that indicates if the watcher should react to local address changes.
+func (configuration *Configuration) GetEvents() bool {
+	return configuration.Events
+}
+
+// GetEventsDebounce returns the time to wait for further local address changes before the records are updated.
+func (configuration *Configuration) GetEventsDebounce() time.Duration {
+	return configuration.EventsDebounce
+}
+
+// GetStateFile returns the path to the file used to persist the state between the synchronizations.
+func (configuration *Configuration) GetStateFile() string {
+	return configuration.StateFile
+}
+
+// GetReconciliationInterval returns the interval at which all records are looked up even if the address has not changed.
+func (configuration *Configuration) GetReconciliationInterval() time.Duration {
+	return configuration.ReconciliationInterval
+}
+
+// GetShutdownTimeout returns the maximum time to wait for the in-flight update to finish when the watcher is stopped.
+func (configuration *Configuration) GetShutdownTimeout() time.Duration {
+	return configuration.ShutdownTimeout
+}
@@ -0,0 +1,392 @@
+// Package dyndns implements the dyndns2 compatible update server which applies the addresses pushed by clients such as routers.
+package dyndns
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"github.com/darki73/goflaresync/pkg/address"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	"github.com/darki73/goflaresync/pkg/configuration/records"
+	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/provider"
+	"github.com/darki73/goflaresync/pkg/watcher"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// UpdatePath is the path of the update endpoint.
+	UpdatePath = "/nic/update"
+	// MaximumHostnames is the maximum number of hostnames which can be updated with a single request.
+	MaximumHostnames = 20
+)
+
+const (
+	// ResponseGood indicates that the hostname was updated.
+	ResponseGood = "good"
+	// ResponseNoChange indicates that the hostname already had the address.
+	ResponseNoChange = "nochg"
+	// ResponseBadAuth indicates that the credentials were not accepted.
+	ResponseBadAuth = "badauth"
+	// ResponseNotFQDN indicates that the hostname is not a fully qualified domain name.
+	ResponseNotFQDN = "notfqdn"
+	// ResponseNoHost indicates that the hostname is not managed or the user is not allowed to update it.
+	ResponseNoHost = "nohost"
+	// ResponseNumHost indicates that too many hostnames were given.
+	ResponseNumHost = "numhost"
+	// ResponseDNSError indicates that the address is invalid or could not be published.
+	ResponseDNSError = "dnserr"
+	// ResponseServerError indicates that the update failed because of a problem on the side of the server.
+	ResponseServerError = "911"
+)
+
+// Updater is the definition of the component which applies the addresses to the monitored records.
+type Updater interface {
+	// UpdateRecords updates the monitored records with the addresses, keyed by the network.
+	UpdateRecords(ctx context.Context, monitoredRecords []*records.Configuration, addresses map[string]netip.Addr) []*watcher.Result
+}
+
+// Server is the definition of the dyndns2 compatible update server.
+type Server struct {
+	// updater applies the addresses to the monitored records.
+	updater Updater
+	// mutex protects the updater.
+	mutex sync.RWMutex
+	// httpServer is the HTTP server, nil if the server was not started.
+	httpServer *http.Server
+	// listener is the listener the server accepts the requests on, nil if the server was not started.
+	listener net.Listener
+	// cancel cancels the context of the in-flight requests and aborts their updates.
+	cancel context.CancelFunc
+	// errors is the channel which receives the error the server stopped serving with.
+	errors chan error
+}
+
+// NewServer returns a new update server which applies the addresses with the given updater.
+func NewServer(updater Updater) *Server {
+	return &Server{
+		updater: updater,
+		errors:  make(chan error, 1),
+	}
+}
+
+// SetUpdater replaces the updater, it is used when the configuration was reloaded.
+func (server *Server) SetUpdater(updater Updater) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.updater = updater
+}
+
+// getUpdater returns the updater.
+func (server *Server) getUpdater() Updater {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	return server.updater
+}
+
+// Start starts listening on the configured address, the requests are served in the background.
+// The certificate is loaded before the server starts listening, so an invalid certificate is reported by Start.
+func (server *Server) Start() error {
+	config := configuration.GetConfiguration().GetDynDNS()
+
+	var tlsConfig *tls.Config
+	if config.GetTLSCertificate() != "" {
+		certificate, err := tls.LoadX509KeyPair(config.GetTLSCertificate(), config.GetTLSKey())
+		if err != nil {
+			return err
+		}
+
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
+	listener, err := net.Listen("tcp", config.GetListen())
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(UpdatePath, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	server.listener = listener
+	server.cancel = cancel
+	server.httpServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	log.InfofWithFields(
+		"listening for dyndns2 updates on `%s`",
+		log.FieldsMap{
+			"source": "dyndns",
+		},
+		listener.Addr().String(),
+	)
+
+	go func() {
+		var err error
+		if tlsConfig != nil {
+			err = server.httpServer.ServeTLS(listener, "", "")
+		} else {
+			err = server.httpServer.Serve(listener)
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.ErrorfWithFields(
+				"dyndns2 update server stopped: %s",
+				log.FieldsMap{
+					"source": "dyndns",
+				},
+				err.Error(),
+			)
+			server.errors <- err
+		}
+	}()
+
+	return nil
+}
+
+// GetErrors returns the channel which receives the error the server stopped serving with, it is not used when the server is stopped with Stop.
+func (server *Server) GetErrors() <-chan error {
+	return server.errors
+}
+
+// GetAddress returns the address the server listens on, empty if the server was not started.
+func (server *Server) GetAddress() string {
+	if server.listener == nil {
+		return ""
+	}
+
+	return server.listener.Addr().String()
+}
+
+// Stop stops accepting the requests and waits for the in-flight requests to finish.
+// Once the context is done, the updates of the remaining requests are aborted and the error of the context is returned.
+func (server *Server) Stop(ctx context.Context) error {
+	if server.httpServer == nil {
+		return nil
+	}
+	defer server.cancel()
+
+	if err := server.httpServer.Shutdown(ctx); err != nil {
+		server.cancel()
+		_ = server.httpServer.Close()
+		return err
+	}
+
+	return nil
+}
+
+// ServeHTTP handles the update requests.
+func (server *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if request.Method != http.MethodGet && request.Method != http.MethodPost {
+		writer.Header().Set("Allow", "GET, POST")
+		http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := authenticate(request)
+	if !ok {
+		writer.Header().Set("WWW-Authenticate", `Basic realm="goflaresync"`)
+		writer.WriteHeader(http.StatusUnauthorized)
+		_, _ = writer.Write([]byte(ResponseBadAuth))
+		return
+	}
+
+	hostnames := splitList(request.FormValue("hostname"))
+	if len(hostnames) == 0 {
+		_, _ = writer.Write([]byte(ResponseNotFQDN))
+		return
+	}
+
+	if len(hostnames) > MaximumHostnames {
+		_, _ = writer.Write([]byte(ResponseNumHost))
+		return
+	}
+
+	addresses, ok := getAddresses(request)
+	if !ok {
+		_, _ = writer.Write([]byte(ResponseDNSError))
+		return
+	}
+
+	responses := make([]string, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		response := server.update(request.Context(), user, hostname, addresses)
+
+		log.InfofWithFields(
+			"update of `%s` requested by `%s`: %s",
+			log.FieldsMap{
+				"remote": request.RemoteAddr,
+				"source": "dyndns",
+			},
+			hostname,
+			user,
+			response,
+		)
+
+		responses = append(responses, response)
+	}
+
+	_, _ = writer.Write([]byte(strings.Join(responses, "\n")))
+}
+
+// update applies the addresses to the records of the hostname and returns the dyndns2 response for it.
+func (server *Server) update(ctx context.Context, username string, hostname string, addresses map[string]netip.Addr) string {
+	hostname = strings.TrimSuffix(strings.ToLower(hostname), ".")
+	if !strings.Contains(hostname, ".") {
+		return ResponseNotFQDN
+	}
+
+	user := configuration.GetConfiguration().GetDynDNS().GetUser(username)
+	if user == nil || !user.IsAllowed(hostname) {
+		return ResponseNoHost
+	}
+
+	monitoredRecords := getMonitoredRecords(hostname, addresses)
+	if len(monitoredRecords) == 0 {
+		return ResponseNoHost
+	}
+
+	results := server.getUpdater().UpdateRecords(ctx, monitoredRecords, addresses)
+	if len(results) < len(monitoredRecords) {
+		return ResponseServerError
+	}
+
+	changed := false
+	for _, result := range results {
+		switch result.GetStatus() {
+		case watcher.StatusFailed:
+			if errors.Is(result.GetError(), provider.ErrInvalidCredentials) {
+				return ResponseServerError
+			}
+			return ResponseDNSError
+		case watcher.StatusSkipped:
+			return ResponseDNSError
+		case watcher.StatusNotFound:
+			return ResponseNoHost
+		case watcher.StatusUpdated, watcher.StatusCreated:
+			changed = true
+		}
+	}
+
+	if changed {
+		return ResponseGood + " " + formatAddresses(addresses)
+	}
+
+	return ResponseNoChange + " " + formatAddresses(addresses)
+}
+
+// authenticate returns the name of the user if the request carries valid credentials.
+func authenticate(request *http.Request) (string, bool) {
+	username, password, ok := request.BasicAuth()
+	if !ok {
+		return "", false
+	}
+
+	user := configuration.GetConfiguration().GetDynDNS().GetUser(username)
+	if user == nil || user.GetPassword() == "" {
+		return "", false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(password), []byte(user.GetPassword())) != 1 {
+		return "", false
+	}
+
+	return user.GetUsername(), true
+}
+
+// getAddresses returns the addresses given by the client, keyed by the network.
+// The address the request was sent from is used when the client does not give one.
+// It returns false if any of the given addresses is invalid.
+func getAddresses(request *http.Request) (map[string]netip.Addr, bool) {
+	values := append(splitList(request.FormValue("myip")), splitList(request.FormValue("myipv6"))...)
+
+	if len(values) == 0 {
+		host, _, err := net.SplitHostPort(request.RemoteAddr)
+		if err != nil {
+			return nil, false
+		}
+		values = []string{host}
+	}
+
+	addresses := make(map[string]netip.Addr)
+	for _, value := range values {
+		externalAddress, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, false
+		}
+
+		externalAddress = externalAddress.Unmap()
+		network := address.NetworkIPv4
+		if externalAddress.Is6() {
+			network = address.NetworkIPv6
+		}
+
+		addresses[network] = externalAddress
+	}
+
+	return addresses, true
+}
+
+// getMonitoredRecords returns the monitored records of the hostname which can be updated with the given addresses.
+func getMonitoredRecords(hostname string, addresses map[string]netip.Addr) []*records.Configuration {
+	var monitoredRecords []*records.Configuration
+
+	for _, monitoredRecord := range configuration.GetConfiguration().GetRecords() {
+		if strings.TrimSuffix(strings.ToLower(monitoredRecord.GetName()), ".") != hostname {
+			continue
+		}
+
+		network, err := address.GetNetworkForRecordType(monitoredRecord.GetType())
+		if err != nil {
+			continue
+		}
+
+		if _, ok := addresses[network]; ok {
+			monitoredRecords = append(monitoredRecords, monitoredRecord)
+		}
+	}
+
+	return monitoredRecords
+}
+
+// formatAddresses returns the addresses separated by commas, the IPv4 address first.
+func formatAddresses(addresses map[string]netip.Addr) string {
+	var formatted []string
+	for _, network := range []string{address.NetworkIPv4, address.NetworkIPv6} {
+		if externalAddress, ok := addresses[network]; ok {
+			formatted = append(formatted, externalAddress.String())
+		}
+	}
+	return strings.Join(formatted, ",")
+}
+
+// splitList returns the non-empty values of the comma separated list.
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
@@ -0,0 +1,389 @@
+package dyndns
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/darki73/goflaresync/pkg/api/apitest"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	dyndnsConfiguration "github.com/darki73/goflaresync/pkg/configuration/dyndns"
+	"github.com/darki73/goflaresync/pkg/configuration/records"
+	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/provider"
+	"github.com/darki73/goflaresync/pkg/provider/registry"
+	"github.com/darki73/goflaresync/pkg/watcher"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	log.SetOutput(io.Discard)
+}
+
+// stubUpdater is the definition of an updater which returns the configured status for every record.
+type stubUpdater struct {
+	status    watcher.Status
+	err       error
+	records   []*records.Configuration
+	addresses map[string]netip.Addr
+}
+
+func (updater *stubUpdater) UpdateRecords(_ context.Context, monitoredRecords []*records.Configuration, addresses map[string]netip.Addr) []*watcher.Result {
+	updater.records = append(updater.records, monitoredRecords...)
+	updater.addresses = addresses
+
+	var results []*watcher.Result
+	for _, monitoredRecord := range monitoredRecords {
+		results = append(results, &watcher.Result{Record: monitoredRecord, Status: updater.status, Error: updater.err})
+	}
+	return results
+}
+
+func setTestConfiguration() {
+	config := configuration.InitializeWithDefaults()
+	config.Records = []*records.Configuration{
+		{Type: "A", Name: "home.example.com"},
+		{Type: "AAAA", Name: "home.example.com"},
+		{Type: "A", Name: "office.example.com"},
+		{Type: "A", Name: "other.example.com"},
+	}
+	config.DynDNS.Users = []*dyndnsConfiguration.User{
+		{Username: "router", Password: "secret", Hostnames: []string{"home.example.com", "Office.Example.com."}},
+	}
+	configuration.SetConfiguration(config)
+}
+
+func sendUpdate(server *Server, query string, username string, password string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, UpdatePath+"?"+query, nil)
+	request.RemoteAddr = "93.184.216.99:51234"
+	if username != "" {
+		request.SetBasicAuth(username, password)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestUpdateResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		username string
+		password string
+		status   watcher.Status
+		err      error
+		code     int
+		expected string
+	}{
+		{name: "missing credentials", query: "hostname=home.example.com&myip=93.184.216.34", code: http.StatusUnauthorized, expected: "badauth"},
+		{name: "wrong password", query: "hostname=home.example.com&myip=93.184.216.34", username: "router", password: "wrong", code: http.StatusUnauthorized, expected: "badauth"},
+		{name: "unknown user", query: "hostname=home.example.com&myip=93.184.216.34", username: "nobody", password: "secret", code: http.StatusUnauthorized, expected: "badauth"},
+		{name: "updated", query: "hostname=home.example.com&myip=93.184.216.34", username: "router", password: "secret", status: watcher.StatusUpdated, code: http.StatusOK, expected: "good 93.184.216.34"},
+		{name: "unchanged", query: "hostname=home.example.com&myip=93.184.216.34", username: "router", password: "secret", status: watcher.StatusUnchanged, code: http.StatusOK, expected: "nochg 93.184.216.34"},
+		{name: "address of the request", query: "hostname=home.example.com", username: "router", password: "secret", status: watcher.StatusUpdated, code: http.StatusOK, expected: "good 93.184.216.99"},
+		{name: "both families", query: "hostname=home.example.com&myip=93.184.216.34,2606:4700::1", username: "router", password: "secret", status: watcher.StatusCreated, code: http.StatusOK, expected: "good 93.184.216.34,2606:4700::1"},
+		{name: "hostname not allowed", query: "hostname=other.example.com&myip=93.184.216.34", username: "router", password: "secret", status: watcher.StatusUpdated, code: http.StatusOK, expected: "nohost"},
+		{name: "hostname without records", query: "hostname=office.example.com&myip=2606:4700::1", username: "router", password: "secret", status: watcher.StatusUpdated, code: http.StatusOK, expected: "nohost"},
+		{name: "not fully qualified", query: "hostname=home&myip=93.184.216.34", username: "router", password: "secret", code: http.StatusOK, expected: "notfqdn"},
+		{name: "missing hostname", query: "myip=93.184.216.34", username: "router", password: "secret", code: http.StatusOK, expected: "notfqdn"},
+		{name: "too many hostnames", query: "hostname=" + strings.Repeat("home.example.com,", 21) + "&myip=93.184.216.34", username: "router", password: "secret", code: http.StatusOK, expected: "numhost"},
+		{name: "invalid address", query: "hostname=home.example.com&myip=invalid", username: "router", password: "secret", code: http.StatusOK, expected: "dnserr"},
+		{name: "record not found", query: "hostname=home.example.com&myip=93.184.216.34", username: "router", password: "secret", status: watcher.StatusNotFound, code: http.StatusOK, expected: "nohost"},
+		{name: "private address", query: "hostname=home.example.com&myip=10.0.0.1", username: "router", password: "secret", status: watcher.StatusSkipped, code: http.StatusOK, expected: "dnserr"},
+		{name: "failed update", query: "hostname=home.example.com&myip=93.184.216.34", username: "router", password: "secret", status: watcher.StatusFailed, err: errors.New("timeout"), code: http.StatusOK, expected: "dnserr"},
+		{name: "rejected credentials", query: "hostname=home.example.com&myip=93.184.216.34", username: "router", password: "secret", status: watcher.StatusFailed, err: provider.ErrInvalidCredentials, code: http.StatusOK, expected: "911"},
+		{name: "several hostnames", query: "hostname=home.example.com,other.example.com&myip=93.184.216.34", username: "router", password: "secret", status: watcher.StatusUpdated, code: http.StatusOK, expected: "good 93.184.216.34\nnohost"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setTestConfiguration()
+
+			recorder := sendUpdate(NewServer(&stubUpdater{status: test.status, err: test.err}), test.query, test.username, test.password)
+
+			if recorder.Code != test.code {
+				t.Errorf("Expected status %d but got %d", test.code, recorder.Code)
+			}
+
+			if recorder.Body.String() != test.expected {
+				t.Errorf("Expected '%s' but got '%s'", test.expected, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateOnlyPassesRecordsOfGivenFamilies(t *testing.T) {
+	setTestConfiguration()
+	updater := &stubUpdater{status: watcher.StatusUpdated}
+
+	sendUpdate(NewServer(updater), "hostname=HOME.example.com.&myip=2606:4700::1", "router", "secret")
+
+	if len(updater.records) != 1 || updater.records[0].GetType() != "AAAA" {
+		t.Errorf("Expected only the AAAA record but got %+v", updater.records)
+	}
+}
+
+func TestUpdateAppliesAddressThroughProvider(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	server.AddZone("example.com")
+	server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1", TTL: 300})
+
+	setTestConfiguration()
+	config := configuration.GetConfiguration()
+	config.Credentials.Token = apitest.Token
+	config.Records = []*records.Configuration{{Type: "A", Name: "home.example.com"}}
+	config.API.BaseURL = server.GetURL()
+	config.API.RateLimit = 0
+	config.API.RetryMinBackoff = time.Millisecond
+
+	providers, err := registry.NewFromConfiguration(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	updateServer := NewServer(watcher.NewWithProviders(providers))
+
+	if body := sendUpdate(updateServer, "hostname=home.example.com&myip=93.184.216.34", "router", "secret").Body.String(); body != "good 93.184.216.34" {
+		t.Errorf("Expected 'good 93.184.216.34' but got '%s'", body)
+	}
+
+	if body := sendUpdate(updateServer, "hostname=home.example.com&myip=93.184.216.34", "router", "secret").Body.String(); body != "nochg 93.184.216.34" {
+		t.Errorf("Expected 'nochg 93.184.216.34' but got '%s'", body)
+	}
+
+	for _, record := range server.GetRecords("example.com") {
+		if record.Content != "93.184.216.34" || record.TTL != 300 {
+			t.Errorf("Expected the address to be applied but got %+v", record)
+		}
+	}
+}
+
+// blockingUpdater is the definition of an updater which blocks until it is released or its context is done.
+type blockingUpdater struct {
+	started chan struct{}
+	release chan struct{}
+	aborted chan struct{}
+}
+
+func newBlockingUpdater() *blockingUpdater {
+	return &blockingUpdater{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		aborted: make(chan struct{}),
+	}
+}
+
+func (updater *blockingUpdater) UpdateRecords(ctx context.Context, monitoredRecords []*records.Configuration, _ map[string]netip.Addr) []*watcher.Result {
+	close(updater.started)
+
+	select {
+	case <-updater.release:
+	case <-ctx.Done():
+		close(updater.aborted)
+	}
+
+	var results []*watcher.Result
+	for _, monitoredRecord := range monitoredRecords {
+		results = append(results, &watcher.Result{Record: monitoredRecord, Status: watcher.StatusUpdated})
+	}
+	return results
+}
+
+func startBlockingServer(t *testing.T) (*Server, *blockingUpdater, chan string) {
+	setTestConfiguration()
+	configuration.GetConfiguration().DynDNS.Listen = "127.0.0.1:0"
+
+	updater := newBlockingUpdater()
+	server := NewServer(updater)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	responses := make(chan string, 1)
+	go func() {
+		request, _ := http.NewRequest(http.MethodGet, "http://"+server.GetAddress()+UpdatePath+"?hostname=home.example.com&myip=93.184.216.34", nil)
+		request.SetBasicAuth("router", "secret")
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer func() {
+			_ = response.Body.Close()
+		}()
+
+		body, _ := io.ReadAll(response.Body)
+		responses <- string(body)
+	}()
+
+	select {
+	case <-updater.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the update to start")
+	}
+
+	return server, updater, responses
+}
+
+func TestStopWaitsForInFlightUpdate(t *testing.T) {
+	server, updater, responses := startBlockingServer(t)
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- server.Stop(context.Background())
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(updater.release)
+
+	if err := <-stopped; err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+
+	if response := <-responses; response != "good 93.184.216.34" {
+		t.Errorf("Expected 'good 93.184.216.34' but got '%s'", response)
+	}
+}
+
+func TestStopAbortsInFlightUpdateAfterTimeout(t *testing.T) {
+	server, updater, _ := startBlockingServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := server.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected '%v' but got '%v'", context.DeadlineExceeded, err)
+	}
+
+	select {
+	case <-updater.aborted:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the in-flight update to be aborted")
+	}
+}
+
+// writeTestCertificate writes a self-signed certificate for `127.0.0.1` along with its key and returns their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	encodedKey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+
+	directory := t.TempDir()
+	certificatePath := filepath.Join(directory, "certificate.pem")
+	keyPath := filepath.Join(directory, "key.pem")
+
+	if err := os.WriteFile(certificatePath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: encodedKey}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	return certificatePath, keyPath
+}
+
+func TestStartServesWithTLSCertificate(t *testing.T) {
+	setTestConfiguration()
+	certificatePath, keyPath := writeTestCertificate(t)
+	configuration.GetConfiguration().DynDNS.Listen = "127.0.0.1:0"
+	configuration.GetConfiguration().DynDNS.TLSCertificate = certificatePath
+	configuration.GetConfiguration().DynDNS.TLSKey = keyPath
+
+	server := NewServer(&stubUpdater{status: watcher.StatusUpdated})
+	if err := server.Start(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	defer func() {
+		_ = server.Stop(context.Background())
+	}()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	request, _ := http.NewRequest(http.MethodGet, "https://"+server.GetAddress()+UpdatePath+"?hostname=home.example.com&myip=93.184.216.34", nil)
+	request.SetBasicAuth("router", "secret")
+
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if body, _ := io.ReadAll(response.Body); string(body) != "good 93.184.216.34" {
+		t.Errorf("Expected 'good 93.184.216.34' but got '%s'", string(body))
+	}
+}
+
+func TestStartRejectsUnreadableTLSCertificate(t *testing.T) {
+	setTestConfiguration()
+	configuration.GetConfiguration().DynDNS.Listen = "127.0.0.1:0"
+	configuration.GetConfiguration().DynDNS.TLSCertificate = filepath.Join(t.TempDir(), "missing.pem")
+
+	server := NewServer(&stubUpdater{status: watcher.StatusUpdated})
+	if err := server.Start(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected '%v' but got '%v'", os.ErrNotExist, err)
+	}
+
+	if address := server.GetAddress(); address != "" {
+		t.Errorf("Expected the server not to listen but it listens on '%s'", address)
+	}
+}
+
+func TestServeErrorIsReported(t *testing.T) {
+	setTestConfiguration()
+	configuration.GetConfiguration().DynDNS.Listen = "127.0.0.1:0"
+
+	server := NewServer(&stubUpdater{status: watcher.StatusUpdated})
+	if err := server.Start(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	_ = server.listener.Close()
+
+	select {
+	case err := <-server.GetErrors():
+		if err == nil {
+			t.Error("Expected an error but got none")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the serve error to be reported")
+	}
+}
@@ -0,0 +1,10 @@
+package provider
+
+import "errors"
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrZoneNotFound        = errors.New("zone not found")
+	ErrUnknownProvider     = errors.New("unknown provider")
+	ErrUnknownProviderType = errors.New("unknown provider type")
+)
@@ -0,0 +1,38 @@
+// Package provider defines the interface every DNS provider has to implement to be kept in sync by the watcher.
+package provider
+
+import (
+	"context"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+)
+
+const (
+	// AttributeProxied is the name of the attribute which indicates if the record is proxied.
+	AttributeProxied = "proxied"
+	// AttributeTTL is the name of the TTL attribute of the record.
+	AttributeTTL = "ttl"
+	// AttributeComment is the name of the comment attribute of the record.
+	AttributeComment = "comment"
+	// AttributeTags is the name of the tags attribute of the record.
+	AttributeTags = "tags"
+)
+
+// Provider is the definition of a DNS provider.
+type Provider interface {
+	// GetName returns the name of the provider instance.
+	GetName() string
+	// SupportsAttribute checks if the provider stores the given record attribute, the unsupported attributes are never compared.
+	SupportsAttribute(attribute string) bool
+	// ListZonesWithContext returns the list of all zones.
+	ListZonesWithContext(ctx context.Context) ([]*entities.Zone, error)
+	// FindZoneWithContext returns the zone with the given name, or ErrZoneNotFound if there is none.
+	FindZoneWithContext(ctx context.Context, name string) (*entities.Zone, error)
+	// FindRecordsWithContext returns the records of the zone with the given name and type.
+	FindRecordsWithContext(ctx context.Context, zone *entities.Zone, name string, recordType string) ([]*entities.Record, error)
+	// CreateRecordWithContext creates the record in the zone and returns the created record.
+	CreateRecordWithContext(ctx context.Context, zone *entities.Zone, record *entities.Record) (*entities.Record, error)
+	// UpdateRecordWithContext updates the record in the zone and returns the updated record.
+	UpdateRecordWithContext(ctx context.Context, zone *entities.Zone, record *entities.Record) (*entities.Record, error)
+	// DeleteRecordWithContext deletes the record from the zone.
+	DeleteRecordWithContext(ctx context.Context, zone *entities.Zone, record *entities.Record) error
+}
@@ -0,0 +1,114 @@
+// Package registry creates the DNS provider instances declared in the configuration.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/api"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	providerConfiguration "github.com/darki73/goflaresync/pkg/configuration/provider"
+	"github.com/darki73/goflaresync/pkg/provider"
+	"github.com/darki73/goflaresync/pkg/provider/rfc2136"
+	"sort"
+)
+
+// Registry is the definition of the registry of the DNS provider instances.
+type Registry struct {
+	// providers is the list of provider instances, keyed by their name.
+	providers map[string]provider.Provider
+}
+
+// New returns a new registry containing the given provider instances.
+func New(providers ...provider.Provider) *Registry {
+	registry := &Registry{
+		providers: make(map[string]provider.Provider),
+	}
+
+	for _, instance := range providers {
+		registry.Add(instance)
+	}
+
+	return registry
+}
+
+// NewFromConfiguration returns a new registry with the provider instances the monitored records refer to.
+// Every provider instance is authenticated, which is aborted when the context is done.
+func NewFromConfiguration(ctx context.Context) (*Registry, error) {
+	config := configuration.GetConfiguration()
+
+	declared := make(map[string]*providerConfiguration.Configuration)
+	for _, providerConfig := range config.GetProviders() {
+		declared[providerConfig.GetName()] = providerConfig
+	}
+
+	registry := New()
+
+	for _, record := range config.GetRecords() {
+		if _, err := registry.Get(record.GetProvider()); err == nil {
+			continue
+		}
+
+		providerConfig, ok := declared[record.GetProvider()]
+		if !ok {
+			return nil, fmt.Errorf("%w: `%s` used by record `%s`", provider.ErrUnknownProvider, record.GetProvider(), record.GetName())
+		}
+
+		instance, err := NewProvider(ctx, providerConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize provider `%s`: %w", providerConfig.GetName(), err)
+		}
+
+		registry.Add(instance)
+	}
+
+	return registry, nil
+}
+
+// NewProvider returns a new provider instance based on the provider configuration.
+func NewProvider(ctx context.Context, config *providerConfiguration.Configuration) (provider.Provider, error) {
+	switch config.GetType() {
+	case providerConfiguration.TypeCloudflare:
+		credentials := config.GetCredentials()
+		if credentials == nil {
+			credentials = configuration.GetConfiguration().GetCredentials()
+		}
+		client, err := api.NewClientWithCredentials(ctx, config.GetName(), credentials)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case providerConfiguration.TypeRFC2136:
+		client, err := rfc2136.New(config.GetName(), config.GetRFC2136())
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", provider.ErrUnknownProviderType, config.GetType())
+	}
+}
+
+// Add adds the provider instance to the registry, replacing the instance with the same name.
+func (registry *Registry) Add(instance provider.Provider) {
+	registry.providers[instance.GetName()] = instance
+}
+
+// Get returns the provider instance with the given name.
+func (registry *Registry) Get(name string) (provider.Provider, error) {
+	instance, ok := registry.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", provider.ErrUnknownProvider, name)
+	}
+
+	return instance, nil
+}
+
+// GetNames returns the sorted names of the provider instances.
+func (registry *Registry) GetNames() []string {
+	names := make([]string, 0, len(registry.providers))
+	for name := range registry.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
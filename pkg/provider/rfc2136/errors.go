@@ -0,0 +1,64 @@
+package rfc2136
+
+import (
+	"errors"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/provider"
+	"github.com/miekg/dns"
+)
+
+var (
+	ErrMissingServer         = errors.New("missing server")
+	ErrInvalidTSIGSecret     = errors.New("invalid TSIG secret")
+	ErrUnknownTSIGAlgorithm  = errors.New("unknown TSIG algorithm")
+	ErrUnsupportedRecordType = errors.New("unsupported record type")
+)
+
+// Error is the definition of an error response returned by the DNS server.
+type Error struct {
+	// Rcode is the response code of the response.
+	Rcode int
+	// TSIGError is the TSIG error code of the response, 0 if the signature was accepted.
+	TSIGError int
+}
+
+// Error returns the description of the error.
+func (err *Error) Error() string {
+	if err.TSIGError != 0 {
+		return fmt.Sprintf("dns server error: %s (tsig %s)", dns.RcodeToString[err.Rcode], dns.RcodeToString[err.TSIGError])
+	}
+
+	return fmt.Sprintf("dns server error: %s", dns.RcodeToString[err.Rcode])
+}
+
+// Unwrap returns the invalid credentials error if the message was refused, otherwise nil.
+func (err *Error) Unwrap() error {
+	if err.IsAuthenticationError() {
+		return provider.ErrInvalidCredentials
+	}
+
+	return nil
+}
+
+// IsAuthenticationError checks if the server refused the message because of the signature or its update policy.
+func (err *Error) IsAuthenticationError() bool {
+	return err.TSIGError != 0 || err.Rcode == dns.RcodeNotAuth || err.Rcode == dns.RcodeRefused
+}
+
+// IsZoneNotFound checks if the server is not authoritative for the queried name.
+// Servers refuse the queries for the names they are not authoritative for, the signature is only rejected when the TSIG error is set.
+func (err *Error) IsZoneNotFound() bool {
+	switch err.Rcode {
+	case dns.RcodeNameError:
+		return true
+	case dns.RcodeRefused, dns.RcodeNotAuth:
+		return err.TSIGError == 0
+	default:
+		return false
+	}
+}
+
+// IsTransient checks if the server failed to process the message and it may succeed if it is sent again later.
+func (err *Error) IsTransient() bool {
+	return err.Rcode == dns.RcodeServerFailure
+}
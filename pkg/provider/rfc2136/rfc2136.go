@@ -0,0 +1,390 @@
+// Package rfc2136 implements the DNS provider which manages the records with RFC 2136 dynamic DNS updates signed with TSIG.
+package rfc2136
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	rfc2136Configuration "github.com/darki73/goflaresync/pkg/configuration/rfc2136"
+	"github.com/darki73/goflaresync/pkg/provider"
+	"github.com/miekg/dns"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// tsigAlgorithms is the list of supported TSIG algorithms, keyed by their configuration name.
+var tsigAlgorithms = map[string]string{
+	"hmac-md5":    dns.HmacMD5,
+	"hmac-sha1":   dns.HmacSHA1,
+	"hmac-sha224": dns.HmacSHA224,
+	"hmac-sha256": dns.HmacSHA256,
+	"hmac-sha384": dns.HmacSHA384,
+	"hmac-sha512": dns.HmacSHA512,
+}
+
+// tsigFudge is the number of seconds the clocks of the client and the server may differ by.
+const tsigFudge = 300
+
+// Client is the definition of the RFC 2136 dynamic DNS update client.
+type Client struct {
+	// name is the name of the provider instance.
+	name string
+	// server is the address of the DNS server, including the port.
+	server string
+	// zones is the list of zones served by the server, empty if the zones are discovered.
+	zones []string
+	// ttl is the TTL of the records created or updated without an explicit TTL.
+	ttl int
+	// keyName is the fully qualified name of the TSIG key, empty if the messages are not signed.
+	keyName string
+	// algorithm is the fully qualified name of the TSIG algorithm.
+	algorithm string
+	// client is the DNS client used to exchange the messages.
+	client *dns.Client
+}
+
+// Client is used by the watcher as a DNS provider.
+var _ provider.Provider = (*Client)(nil)
+
+// New returns a new RFC 2136 client with the given provider instance name.
+func New(name string, config *rfc2136Configuration.Configuration) (*Client, error) {
+	if config == nil || config.GetServer() == "" {
+		return nil, ErrMissingServer
+	}
+
+	client := &Client{
+		name:   name,
+		server: config.GetServer(),
+		zones:  config.GetZones(),
+		ttl:    config.GetTTL(),
+		client: &dns.Client{
+			Net:     config.GetProtocol(),
+			Timeout: config.GetTimeout(),
+		},
+	}
+
+	if config.GetTSIGKeyName() == "" {
+		return client, nil
+	}
+
+	algorithm, ok := tsigAlgorithms[config.GetTSIGAlgorithm()]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTSIGAlgorithm, config.GetTSIGAlgorithm())
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(config.GetTSIGSecret()); err != nil || config.GetTSIGSecret() == "" {
+		return nil, fmt.Errorf("%w: the secret of key `%s` has to be base64 encoded", ErrInvalidTSIGSecret, config.GetTSIGKeyName())
+	}
+
+	client.keyName = dns.CanonicalName(config.GetTSIGKeyName())
+	client.algorithm = algorithm
+	client.client.TsigSecret = map[string]string{
+		client.keyName: config.GetTSIGSecret(),
+	}
+
+	return client, nil
+}
+
+// GetName returns the name of the provider instance.
+func (client *Client) GetName() string {
+	return client.name
+}
+
+// SupportsAttribute checks if the record attribute is stored by the DNS server, only the TTL exists outside of Cloudflare.
+func (client *Client) SupportsAttribute(attribute string) bool {
+	return attribute == provider.AttributeTTL
+}
+
+// ListZonesWithContext returns the list of the configured zones.
+// The zones which are discovered with SOA queries are not listed.
+func (client *Client) ListZonesWithContext(_ context.Context) ([]*entities.Zone, error) {
+	zones := make([]*entities.Zone, 0, len(client.zones))
+	for _, zone := range client.zones {
+		zones = append(zones, newZone(zone))
+	}
+	return zones, nil
+}
+
+// FindZoneWithContext returns the zone with the given name, or ErrZoneNotFound if the server is not authoritative for it.
+// When no zones are configured, the zone is discovered by querying the SOA record of the name.
+func (client *Client) FindZoneWithContext(ctx context.Context, name string) (*entities.Zone, error) {
+	name = normalizeName(name)
+
+	if len(client.zones) > 0 {
+		for _, zone := range client.zones {
+			if zone == name {
+				return newZone(zone), nil
+			}
+		}
+		return nil, provider.ErrZoneNotFound
+	}
+
+	message := new(dns.Msg)
+	message.SetQuestion(dns.Fqdn(name), dns.TypeSOA)
+	message.RecursionDesired = false
+
+	response, err := client.exchange(ctx, message)
+	if err != nil {
+		var dnsError *Error
+		if errors.As(err, &dnsError) && dnsError.IsZoneNotFound() {
+			return nil, provider.ErrZoneNotFound
+		}
+		return nil, err
+	}
+
+	for _, answer := range response.Answer {
+		if soa, ok := answer.(*dns.SOA); ok && normalizeName(soa.Hdr.Name) == name {
+			return newZone(name), nil
+		}
+	}
+
+	return nil, provider.ErrZoneNotFound
+}
+
+// FindRecordsWithContext returns the records of the zone with the given name and type.
+func (client *Client) FindRecordsWithContext(ctx context.Context, zone *entities.Zone, name string, recordType string) ([]*entities.Record, error) {
+	rrType, err := getRRType(recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	message := new(dns.Msg)
+	message.SetQuestion(dns.Fqdn(normalizeName(name)), rrType)
+	message.RecursionDesired = false
+
+	response, err := client.exchange(ctx, message)
+	if err != nil {
+		var dnsError *Error
+		if errors.As(err, &dnsError) && dnsError.Rcode == dns.RcodeNameError {
+			return []*entities.Record{}, nil
+		}
+		return nil, err
+	}
+
+	records := make([]*entities.Record, 0, len(response.Answer))
+	for _, answer := range response.Answer {
+		if answer.Header().Rrtype != rrType || normalizeName(answer.Header().Name) != normalizeName(name) {
+			continue
+		}
+
+		content, ok := getContent(answer)
+		if !ok {
+			continue
+		}
+
+		records = append(records, &entities.Record{
+			ID:       content,
+			Name:     normalizeName(answer.Header().Name),
+			Type:     recordType,
+			Content:  content,
+			TTL:      int(answer.Header().Ttl),
+			ZoneID:   zone.ID,
+			ZoneName: zone.Name,
+		})
+	}
+
+	return records, nil
+}
+
+// CreateRecordWithContext adds the record to the zone and returns the created record.
+func (client *Client) CreateRecordWithContext(ctx context.Context, zone *entities.Zone, record *entities.Record) (*entities.Record, error) {
+	rr, err := client.newRR(record.Name, record.Type, record.TTL, record.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	message := new(dns.Msg)
+	message.SetUpdate(dns.Fqdn(zone.Name))
+	message.Insert([]dns.RR{rr})
+
+	if _, err := client.exchange(ctx, message); err != nil {
+		return nil, err
+	}
+
+	return client.getRecordResult(zone, record), nil
+}
+
+// UpdateRecordWithContext replaces the record in the zone and returns the updated record.
+// The previous content of the record is taken from its identifier.
+func (client *Client) UpdateRecordWithContext(ctx context.Context, zone *entities.Zone, record *entities.Record) (*entities.Record, error) {
+	previous, err := client.newRR(record.Name, record.Type, 0, record.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rr, err := client.newRR(record.Name, record.Type, record.TTL, record.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	message := new(dns.Msg)
+	message.SetUpdate(dns.Fqdn(zone.Name))
+	message.Remove([]dns.RR{previous})
+	message.Insert([]dns.RR{rr})
+
+	if _, err := client.exchange(ctx, message); err != nil {
+		return nil, err
+	}
+
+	return client.getRecordResult(zone, record), nil
+}
+
+// DeleteRecordWithContext removes the record from the zone.
+func (client *Client) DeleteRecordWithContext(ctx context.Context, zone *entities.Zone, record *entities.Record) error {
+	rr, err := client.newRR(record.Name, record.Type, 0, record.ID)
+	if err != nil {
+		return err
+	}
+
+	message := new(dns.Msg)
+	message.SetUpdate(dns.Fqdn(zone.Name))
+	message.Remove([]dns.RR{rr})
+
+	_, err = client.exchange(ctx, message)
+	return err
+}
+
+// exchange signs the message, sends it to the server and returns the response.
+// Truncated UDP responses are retried over TCP.
+func (client *Client) exchange(ctx context.Context, message *dns.Msg) (*dns.Msg, error) {
+	response, err := client.send(ctx, client.client, message)
+	if err == nil && response.Truncated && client.client.Net != rfc2136Configuration.ProtocolTCP {
+		tcpClient := *client.client
+		tcpClient.Net = rfc2136Configuration.ProtocolTCP
+		response, err = client.send(ctx, &tcpClient, message)
+	}
+
+	if response != nil && response.Rcode != dns.RcodeSuccess {
+		dnsError := &Error{
+			Rcode: response.Rcode,
+		}
+		if tsig := response.IsTsig(); tsig != nil {
+			dnsError.TSIGError = int(tsig.Error)
+		}
+		return nil, dnsError
+	}
+
+	if errors.Is(err, dns.ErrSig) || errors.Is(err, dns.ErrTime) || errors.Is(err, dns.ErrSecret) {
+		return nil, fmt.Errorf("%w: the signature of the response was not accepted: %s", provider.ErrInvalidCredentials, err.Error())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// send signs the message and sends it to the server with the given DNS client.
+// The signature is removed from the message when it is sent, so it is added again on every call.
+func (client *Client) send(ctx context.Context, dnsClient *dns.Client, message *dns.Msg) (*dns.Msg, error) {
+	if client.keyName != "" {
+		message.SetTsig(client.keyName, client.algorithm, tsigFudge, time.Now().Unix())
+	}
+
+	response, _, err := dnsClient.ExchangeContext(ctx, message, client.server)
+	return response, err
+}
+
+// newRR returns the resource record with the given name, type, TTL and content.
+func (client *Client) newRR(name string, recordType string, ttl int, content string) (dns.RR, error) {
+	rrType, err := getRRType(recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := netip.ParseAddr(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content `%s` of record `%s`: %w", content, name, err)
+	}
+
+	header := dns.RR_Header{
+		Name:   dns.Fqdn(normalizeName(name)),
+		Rrtype: rrType,
+		Class:  dns.ClassINET,
+		Ttl:    uint32(client.getTTL(ttl)),
+	}
+
+	if rrType == dns.TypeA {
+		if !address.Is4() {
+			return nil, fmt.Errorf("invalid content `%s` of record `%s`: not an IPv4 address", content, name)
+		}
+		return &dns.A{Hdr: header, A: address.AsSlice()}, nil
+	}
+
+	if !address.Is6() {
+		return nil, fmt.Errorf("invalid content `%s` of record `%s`: not an IPv6 address", content, name)
+	}
+	return &dns.AAAA{Hdr: header, AAAA: address.AsSlice()}, nil
+}
+
+// getTTL returns the given TTL, or the configured TTL if the record uses the automatic TTL.
+func (client *Client) getTTL(ttl int) int {
+	if ttl <= 1 {
+		return client.ttl
+	}
+
+	return ttl
+}
+
+// getRecordResult returns a copy of the record as it is stored by the server.
+func (client *Client) getRecordResult(zone *entities.Zone, record *entities.Record) *entities.Record {
+	result := *record
+	result.ID = record.Content
+	result.Name = normalizeName(record.Name)
+	result.TTL = client.getTTL(record.TTL)
+	result.ZoneID = zone.ID
+	result.ZoneName = zone.Name
+	return &result
+}
+
+// getRRType returns the resource record type for the given record type.
+func getRRType(recordType string) (uint16, error) {
+	switch strings.ToUpper(recordType) {
+	case "A":
+		return dns.TypeA, nil
+	case "AAAA":
+		return dns.TypeAAAA, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedRecordType, recordType)
+	}
+}
+
+// getContent returns the address of the resource record.
+func getContent(rr dns.RR) (string, bool) {
+	var address netip.Addr
+	var ok bool
+
+	switch typed := rr.(type) {
+	case *dns.A:
+		address, ok = netip.AddrFromSlice(typed.A)
+	case *dns.AAAA:
+		address, ok = netip.AddrFromSlice(typed.AAAA)
+	}
+
+	if !ok {
+		return "", false
+	}
+
+	if rr.Header().Rrtype == dns.TypeA {
+		address = address.Unmap()
+	}
+
+	return address.String(), true
+}
+
+// newZone returns the zone with the given name, the name is used as the identifier of the zone.
+func newZone(name string) *entities.Zone {
+	return &entities.Zone{
+		ID:   name,
+		Name: name,
+	}
+}
+
+// normalizeName returns the name in lower case and without the trailing dot.
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
@@ -0,0 +1,209 @@
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	rfc2136Configuration "github.com/darki73/goflaresync/pkg/configuration/rfc2136"
+	"github.com/darki73/goflaresync/pkg/provider"
+	"github.com/darki73/goflaresync/pkg/provider/rfc2136/rfc2136test"
+	"testing"
+)
+
+func newTestClient(t *testing.T, server *rfc2136test.Server, secret string) *Client {
+	config := rfc2136Configuration.InitializeWithDefaults()
+	config.Server = server.GetAddress()
+	config.TSIGKeyName = rfc2136test.KeyName
+	config.TSIGSecret = secret
+	config.TSIGAlgorithm = rfc2136test.Algorithm
+
+	client, err := New("internal", config)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	return client
+}
+
+func TestNewValidatesConfiguration(t *testing.T) {
+	tests := []struct {
+		config   *rfc2136Configuration.Configuration
+		expected error
+	}{
+		{config: nil, expected: ErrMissingServer},
+		{config: &rfc2136Configuration.Configuration{}, expected: ErrMissingServer},
+		{config: &rfc2136Configuration.Configuration{Server: "ns1.example.com", TSIGKeyName: "key", TSIGSecret: "not base64!"}, expected: ErrInvalidTSIGSecret},
+		{config: &rfc2136Configuration.Configuration{Server: "ns1.example.com", TSIGKeyName: "key", TSIGSecret: rfc2136test.Secret, TSIGAlgorithm: "hmac-sha3"}, expected: ErrUnknownTSIGAlgorithm},
+		{config: &rfc2136Configuration.Configuration{Server: "ns1.example.com"}, expected: nil},
+	}
+
+	for _, test := range tests {
+		if _, err := New("internal", test.config); !errors.Is(err, test.expected) {
+			t.Errorf("Expected '%v' but got '%v'", test.expected, err)
+		}
+	}
+}
+
+func TestGetServerAddsDefaultPort(t *testing.T) {
+	tests := []struct {
+		server string
+		output string
+	}{
+		{"ns1.example.com", "ns1.example.com:53"},
+		{"ns1.example.com:5353", "ns1.example.com:5353"},
+		{"2001:db8::53", "[2001:db8::53]:53"},
+		{"[2001:db8::53]:5353", "[2001:db8::53]:5353"},
+	}
+
+	for _, test := range tests {
+		config := &rfc2136Configuration.Configuration{Server: test.server}
+		if config.GetServer() != test.output {
+			t.Errorf("Expected '%s' but got '%s'", test.output, config.GetServer())
+		}
+	}
+}
+
+func TestFindZoneQueriesSOA(t *testing.T) {
+	server := rfc2136test.NewServer()
+	defer server.Close()
+	server.AddZone("example.com")
+
+	client := newTestClient(t, server, rfc2136test.Secret)
+
+	zone, err := client.FindZoneWithContext(context.Background(), "Example.com.")
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if zone.Name != "example.com" {
+		t.Errorf("Expected 'example.com' but got '%s'", zone.Name)
+	}
+
+	if _, err := client.FindZoneWithContext(context.Background(), "home.example.com"); !errors.Is(err, provider.ErrZoneNotFound) {
+		t.Errorf("Expected '%v' but got '%v'", provider.ErrZoneNotFound, err)
+	}
+}
+
+func TestFindZoneOfRefusedParent(t *testing.T) {
+	server := rfc2136test.NewServer()
+	defer server.Close()
+	server.AddZone("home.example.com")
+
+	if _, err := newTestClient(t, server, rfc2136test.Secret).FindZoneWithContext(context.Background(), "example.com"); !errors.Is(err, provider.ErrZoneNotFound) {
+		t.Errorf("Expected '%v' but got '%v'", provider.ErrZoneNotFound, err)
+	}
+
+	if _, err := newTestClient(t, server, "d3Jvbmctc2VjcmV0").FindZoneWithContext(context.Background(), "example.com"); !errors.Is(err, provider.ErrInvalidCredentials) {
+		t.Errorf("Expected '%v' but got '%v'", provider.ErrInvalidCredentials, err)
+	}
+}
+
+func TestCreateUpdateAndDeleteRecord(t *testing.T) {
+	server := rfc2136test.NewServer()
+	defer server.Close()
+	server.AddZone("example.com")
+	server.AddRecord("example.com", "other.example.com. 300 IN A 192.0.2.9")
+
+	client := newTestClient(t, server, rfc2136test.Secret)
+	ctx := context.Background()
+	zone := &entities.Zone{ID: "example.com", Name: "example.com"}
+
+	created, err := client.CreateRecordWithContext(ctx, zone, &entities.Record{Name: "home.example.com", Type: "A", Content: "192.0.2.1", TTL: 1})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if created.ID != "192.0.2.1" || created.TTL != rfc2136Configuration.DefaultTTL {
+		t.Errorf("Expected the created record to use the default TTL but got %+v", created)
+	}
+
+	records, err := client.FindRecordsWithContext(ctx, zone, "home.example.com", "A")
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if len(records) != 1 || records[0].Content != "192.0.2.1" {
+		t.Fatalf("Expected record with '192.0.2.1' but got %+v", records)
+	}
+
+	desired := *records[0]
+	desired.Content = "192.0.2.2"
+
+	updated, err := client.UpdateRecordWithContext(ctx, zone, &desired)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if updated.ID != "192.0.2.2" {
+		t.Errorf("Expected '192.0.2.2' but got '%s'", updated.ID)
+	}
+
+	if contents := server.GetContents("home.example.com", "A"); len(contents) != 1 || contents[0] != "192.0.2.2" {
+		t.Errorf("Expected only '192.0.2.2' but got %v", contents)
+	}
+
+	if err := client.DeleteRecordWithContext(ctx, zone, updated); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if contents := server.GetContents("home.example.com", "A"); len(contents) != 0 {
+		t.Errorf("Expected no records but got %v", contents)
+	}
+
+	if contents := server.GetContents("other.example.com", "A"); len(contents) != 1 {
+		t.Errorf("Expected unrelated records to be left untouched but got %v", contents)
+	}
+}
+
+func TestFindRecordsOfMissingName(t *testing.T) {
+	server := rfc2136test.NewServer()
+	defer server.Close()
+	server.AddZone("example.com")
+
+	records, err := newTestClient(t, server, rfc2136test.Secret).FindRecordsWithContext(
+		context.Background(),
+		&entities.Zone{ID: "example.com", Name: "example.com"},
+		"home.example.com",
+		"AAAA",
+	)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if len(records) != 0 {
+		t.Errorf("Expected no records but got %+v", records)
+	}
+}
+
+func TestRejectedKeyIsAuthenticationError(t *testing.T) {
+	server := rfc2136test.NewServer()
+	defer server.Close()
+	server.AddZone("example.com")
+
+	client := newTestClient(t, server, "d3Jvbmctc2VjcmV0")
+
+	_, err := client.CreateRecordWithContext(
+		context.Background(),
+		&entities.Zone{ID: "example.com", Name: "example.com"},
+		&entities.Record{Name: "home.example.com", Type: "A", Content: "192.0.2.1"},
+	)
+	if !errors.Is(err, provider.ErrInvalidCredentials) {
+		t.Errorf("Expected '%v' but got '%v'", provider.ErrInvalidCredentials, err)
+	}
+
+	if server.CountUpdates() != 0 {
+		t.Errorf("Expected no updates but got %d", server.CountUpdates())
+	}
+}
+
+func TestUnsupportedRecordType(t *testing.T) {
+	client, err := New("internal", &rfc2136Configuration.Configuration{Server: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	_, err = client.FindRecordsWithContext(context.Background(), &entities.Zone{Name: "example.com"}, "example.com", "MX")
+	if !errors.Is(err, ErrUnsupportedRecordType) {
+		t.Errorf("Expected '%v' but got '%v'", ErrUnsupportedRecordType, err)
+	}
+}
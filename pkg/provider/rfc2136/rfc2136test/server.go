@@ -0,0 +1,280 @@
+// Package rfc2136test provides an in-process authoritative DNS server which accepts RFC 2136 updates,
+// it is meant to be used by tests.
+package rfc2136test
+
+import (
+	"fmt"
+	"github.com/miekg/dns"
+	"net"
+	"strings"
+	"sync"
+)
+
+const (
+	// KeyName is the name of the TSIG key accepted by the server.
+	KeyName = "goflaresync."
+	// Secret is the base64 encoded secret of the TSIG key accepted by the server.
+	Secret = "c2VjcmV0LXVzZWQtYnktZ29mbGFyZXN5bmMtdGVzdHM="
+	// Algorithm is the algorithm of the TSIG key accepted by the server.
+	Algorithm = "hmac-sha256"
+)
+
+// Server is the definition of the in-process authoritative DNS server.
+type Server struct {
+	// server is the DNS server listening on the loopback interface.
+	server *dns.Server
+	// mutex protects the zones and the counters.
+	mutex sync.Mutex
+	// zones is the list of records of every zone, keyed by the zone name.
+	zones map[string][]dns.RR
+	// updates is the number of accepted update messages.
+	updates int
+	// queries is the number of answered queries.
+	queries int
+}
+
+// NewServer starts a new server listening over UDP on a random port of the loopback interface.
+func NewServer() *Server {
+	connection, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("rfc2136test: failed to listen: %v", err))
+	}
+
+	server := &Server{
+		zones: make(map[string][]dns.RR),
+	}
+
+	started := make(chan struct{})
+	server.server = &dns.Server{
+		PacketConn: connection,
+		Handler:    dns.HandlerFunc(server.handle),
+		MsgAcceptFunc: func(header dns.Header) dns.MsgAcceptAction {
+			if int(header.Bits>>11)&0xF == dns.OpcodeUpdate {
+				return dns.MsgAccept
+			}
+			return dns.DefaultMsgAcceptFunc(header)
+		},
+		TsigSecret: map[string]string{
+			KeyName: Secret,
+		},
+		NotifyStartedFunc: func() {
+			close(started)
+		},
+	}
+
+	go func() {
+		_ = server.server.ActivateAndServe()
+	}()
+	<-started
+
+	return server
+}
+
+// Close stops the server.
+func (server *Server) Close() {
+	_ = server.server.Shutdown()
+}
+
+// GetAddress returns the address the server listens on.
+func (server *Server) GetAddress() string {
+	return server.server.PacketConn.LocalAddr().String()
+}
+
+// AddZone adds an empty zone with the given name to the server.
+func (server *Server) AddZone(name string) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.zones[dns.CanonicalName(name)] = []dns.RR{}
+}
+
+// AddRecord adds the record given in the zone file format, for example `home.example.com. 300 IN A 192.0.2.1`.
+func (server *Server) AddRecord(zoneName string, record string) {
+	rr, err := dns.NewRR(record)
+	if err != nil {
+		panic(fmt.Sprintf("rfc2136test: invalid record `%s`: %v", record, err))
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	zone := dns.CanonicalName(zoneName)
+	server.zones[zone] = append(server.zones[zone], rr)
+}
+
+// GetContents returns the contents of the records with the given name and type.
+func (server *Server) GetContents(name string, recordType string) []string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	var contents []string
+	for _, records := range server.zones {
+		for _, rr := range records {
+			if rr.Header().Name == dns.CanonicalName(name) && dns.TypeToString[rr.Header().Rrtype] == strings.ToUpper(recordType) {
+				contents = append(contents, strings.TrimPrefix(rr.String(), rr.Header().String()))
+			}
+		}
+	}
+
+	return contents
+}
+
+// CountUpdates returns the number of accepted update messages.
+func (server *Server) CountUpdates() int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.updates
+}
+
+// CountQueries returns the number of answered queries.
+func (server *Server) CountQueries() int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.queries
+}
+
+// handle answers the queries and applies the updates, only messages signed with the known key are accepted.
+func (server *Server) handle(writer dns.ResponseWriter, request *dns.Msg) {
+	response := new(dns.Msg)
+	response.SetReply(request)
+	response.Authoritative = true
+
+	tsig := request.IsTsig()
+	if tsig == nil {
+		response.Rcode = dns.RcodeRefused
+		_ = writer.WriteMsg(response)
+		return
+	}
+
+	// The response to a message with an invalid signature carries the TSIG error and is not signed.
+	if writer.TsigStatus() != nil {
+		response.Rcode = dns.RcodeNotAuth
+		response.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, int64(tsig.TimeSigned))
+		response.IsTsig().Error = dns.RcodeBadSig
+		_ = writer.WriteMsg(response)
+		return
+	}
+
+	server.mutex.Lock()
+	if request.Opcode == dns.OpcodeUpdate {
+		response.Rcode = server.update(request)
+	} else {
+		response.Rcode = server.query(request, response)
+	}
+	server.mutex.Unlock()
+
+	response.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, int64(tsig.TimeSigned))
+	_ = writer.WriteMsg(response)
+}
+
+// query answers the question of the request and returns the response code.
+func (server *Server) query(request *dns.Msg, response *dns.Msg) int {
+	server.queries++
+
+	question := request.Question[0]
+	name := dns.CanonicalName(question.Name)
+
+	if records, ok := server.zones[name]; ok && question.Qtype == dns.TypeSOA {
+		response.Answer = append(response.Answer, &dns.SOA{
+			Hdr:     dns.RR_Header{Name: name, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 300},
+			Ns:      "ns." + name,
+			Mbox:    "hostmaster." + name,
+			Serial:  uint32(len(records) + 1),
+			Refresh: 3600,
+			Retry:   600,
+			Expire:  86400,
+			Minttl:  300,
+		})
+		return dns.RcodeSuccess
+	}
+
+	zone := server.getZone(name)
+	if zone == "" {
+		return dns.RcodeRefused
+	}
+
+	exists := false
+	for _, rr := range server.zones[zone] {
+		if rr.Header().Name != name {
+			continue
+		}
+		exists = true
+		if rr.Header().Rrtype == question.Qtype {
+			response.Answer = append(response.Answer, dns.Copy(rr))
+		}
+	}
+
+	if !exists {
+		return dns.RcodeNameError
+	}
+
+	return dns.RcodeSuccess
+}
+
+// update applies the update section of the request and returns the response code.
+func (server *Server) update(request *dns.Msg) int {
+	zone := dns.CanonicalName(request.Question[0].Name)
+	records, ok := server.zones[zone]
+	if !ok {
+		return dns.RcodeNotAuth
+	}
+
+	for _, change := range request.Ns {
+		header := change.Header()
+		header.Name = dns.CanonicalName(header.Name)
+
+		switch header.Class {
+		case dns.ClassNONE:
+			records = removeRecords(records, func(rr dns.RR) bool {
+				return isSameRecord(rr, change)
+			})
+		case dns.ClassANY:
+			records = removeRecords(records, func(rr dns.RR) bool {
+				return rr.Header().Name == header.Name && (header.Rrtype == dns.TypeANY || rr.Header().Rrtype == header.Rrtype)
+			})
+		default:
+			records = removeRecords(records, func(rr dns.RR) bool {
+				return isSameRecord(rr, change)
+			})
+			records = append(records, dns.Copy(change))
+		}
+	}
+
+	server.zones[zone] = records
+	server.updates++
+
+	return dns.RcodeSuccess
+}
+
+// getZone returns the name of the longest zone the name belongs to, or an empty string if there is none.
+func (server *Server) getZone(name string) string {
+	zone := ""
+	for candidate := range server.zones {
+		if dns.IsSubDomain(candidate, name) && len(candidate) > len(zone) {
+			zone = candidate
+		}
+	}
+	return zone
+}
+
+// removeRecords returns the records without the ones matching the predicate.
+func removeRecords(records []dns.RR, predicate func(rr dns.RR) bool) []dns.RR {
+	remaining := make([]dns.RR, 0, len(records))
+	for _, rr := range records {
+		if !predicate(rr) {
+			remaining = append(remaining, rr)
+		}
+	}
+	return remaining
+}
+
+// isSameRecord checks if both records have the same name, type and data regardless of their class and TTL.
+func isSameRecord(first dns.RR, second dns.RR) bool {
+	if first.Header().Name != second.Header().Name || first.Header().Rrtype != second.Header().Rrtype {
+		return false
+	}
+
+	return strings.TrimPrefix(first.String(), first.Header().String()) == strings.TrimPrefix(second.String(), second.Header().String())
+}
@@ -118,3 +118,35 @@ func isServiceExists(baseDirectory string, serviceName string) bool {
 	}
 	return true
 }
+
+// createSupervisedService creates the service directory with the given run script and the run script of its logger.
+func createSupervisedService(serviceDirectory string, runScript string, logRunScript string) error {
+	if err := os.MkdirAll(path.Join(serviceDirectory, "log"), 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path.Join(serviceDirectory, "run"), []byte(runScript), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(serviceDirectory, "log", "run"), []byte(logRunScript), 0755)
+}
+
+// isSymbolicLinkExists checks if the symbolic link exists, regardless of whether its target exists.
+func isSymbolicLinkExists(linkPath string) bool {
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeSymlink != 0
+}
+
+// getFirstExistingDirectory returns the first of the directories which exists, or the first one if none of them exists.
+func getFirstExistingDirectory(directories []string) string {
+	for _, directory := range directories {
+		if helpers.IsDirectoryExists(directory) {
+			return directory
+		}
+	}
+	return directories[0]
+}
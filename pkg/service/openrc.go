@@ -0,0 +1,323 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+)
+
+// OpenRCConfigurator is the OpenRC configurator.
+type OpenRCConfigurator struct {
+	// baseDirectory is the base directory for service installation
+	baseDirectory string
+	// runlevelsDirectory is the directory containing the runlevels the service is added to
+	runlevelsDirectory string
+	// serviceName is the name of the service
+	serviceName string
+	// processIdentifierHandler is the process identifier handler
+	processIdentifierHandler *ProcessIdentifierHandler
+}
+
+// NewOpenRCConfigurator creates a new OpenRC configurator.
+func NewOpenRCConfigurator() *OpenRCConfigurator {
+	return newOpenRCConfigurator("/")
+}
+
+// newOpenRCConfigurator creates a new OpenRC configurator which manages the service below the given root directory.
+func newOpenRCConfigurator(rootDirectory string) *OpenRCConfigurator {
+	return &OpenRCConfigurator{
+		baseDirectory:            path.Join(rootDirectory, "etc", "init.d"),
+		runlevelsDirectory:       path.Join(rootDirectory, "etc", "runlevels"),
+		serviceName:              applicationName(),
+		processIdentifierHandler: NewProcessIdentifierHandler(),
+	}
+}
+
+// GetServiceTemplate returns the service template.
+func (openRCConfigurator *OpenRCConfigurator) GetServiceTemplate() (string, error) {
+	executablePath, err := applicationFullPath()
+	if err != nil {
+		return "", err
+	}
+
+	scriptInformation := []string{
+		"#!/sbin/openrc-run",
+		fmt.Sprintf("# %s", openRCConfigurator.GetServicePath()),
+		"",
+		fmt.Sprintf("name=\"%s\"", openRCConfigurator.serviceName),
+		"description=\"GoFlareSync Service\"",
+		"",
+		"supervisor=\"supervise-daemon\"",
+		fmt.Sprintf("command=\"%s\"", executablePath),
+		"command_args=\"start\"",
+		"respawn_delay=5",
+		"retry=\"TERM/35/KILL/5\"",
+		fmt.Sprintf("output_log=\"/var/log/%s.log\"", openRCConfigurator.serviceName),
+		fmt.Sprintf("error_log=\"/var/log/%s.log\"", openRCConfigurator.serviceName),
+		"",
+	}
+
+	dependFunction := []string{
+		"depend() {",
+		"	need net",
+		"	after firewall",
+		"}",
+	}
+
+	return combineSlices(scriptInformation, dependFunction), nil
+}
+
+// GetServicePath returns the service path.
+func (openRCConfigurator *OpenRCConfigurator) GetServicePath() string {
+	return path.Join(openRCConfigurator.baseDirectory, openRCConfigurator.serviceName)
+}
+
+// GetProcessIdentifierHandler returns the process identifier handler.
+func (openRCConfigurator *OpenRCConfigurator) GetProcessIdentifierHandler() *ProcessIdentifierHandler {
+	return openRCConfigurator.processIdentifierHandler
+}
+
+// CreateService creates the service.
+func (openRCConfigurator *OpenRCConfigurator) CreateService() error {
+	openRCConfigurator.IsRunningAsRoot()
+
+	if openRCConfigurator.IsServiceExists() {
+		logInfo("service already exists", nil)
+		return nil
+	}
+
+	logInfo("creating the service", nil)
+
+	template, err := openRCConfigurator.GetServiceTemplate()
+
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(openRCConfigurator.GetServicePath(), []byte(template), 0755)
+
+	if err != nil {
+		logDebugf("failed to write service file: %s", nil, err.Error())
+		return err
+	}
+
+	logInfo("successfully created the service", nil)
+
+	return openRCConfigurator.ReloadManager()
+}
+
+// DeleteService deletes the service.
+func (openRCConfigurator *OpenRCConfigurator) DeleteService() error {
+	openRCConfigurator.IsRunningAsRoot()
+
+	if !openRCConfigurator.IsServiceExists() {
+		logInfo("service does not exist", nil)
+		return nil
+	}
+
+	logInfo("deleting the service", nil)
+
+	isRunning, err := openRCConfigurator.IsServiceRunning()
+
+	if err != nil {
+		return err
+	}
+
+	if isRunning {
+		if err := openRCConfigurator.StopService(); err != nil {
+			return err
+		}
+	}
+
+	if err := openRCConfigurator.DisableService(); err != nil {
+		return err
+	}
+
+	if err := os.Remove(openRCConfigurator.GetServicePath()); err != nil {
+		return err
+	}
+
+	logInfo("successfully deleted the service", nil)
+
+	return openRCConfigurator.ReloadManager()
+}
+
+// EnableService enables the service.
+func (openRCConfigurator *OpenRCConfigurator) EnableService() error {
+	openRCConfigurator.IsRunningAsRoot()
+
+	if !openRCConfigurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("enabling the service", nil)
+
+	isEnabled, err := openRCConfigurator.IsServiceEnabled()
+	if err != nil {
+		return err
+	}
+
+	if !isEnabled {
+		if err := exec.Command("rc-update", "add", openRCConfigurator.serviceName, "default").Run(); err != nil {
+			logDebugf("failed to enable the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully enabled the service", nil)
+	} else {
+		logInfo("service is already enabled", nil)
+	}
+
+	return nil
+}
+
+// DisableService disables the service.
+func (openRCConfigurator *OpenRCConfigurator) DisableService() error {
+	openRCConfigurator.IsRunningAsRoot()
+
+	if !openRCConfigurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("disabling the service", nil)
+
+	isEnabled, err := openRCConfigurator.IsServiceEnabled()
+	if err != nil {
+		return err
+	}
+
+	if isEnabled {
+		if err := exec.Command("rc-update", "delete", openRCConfigurator.serviceName).Run(); err != nil {
+			logDebugf("failed to disable the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully disabled the service", nil)
+	} else {
+		logInfo("service is already disabled", nil)
+	}
+
+	return nil
+}
+
+// StartService starts the service.
+func (openRCConfigurator *OpenRCConfigurator) StartService() error {
+	openRCConfigurator.IsRunningAsRoot()
+
+	if !openRCConfigurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("starting the service", nil)
+
+	isRunning, err := openRCConfigurator.IsServiceRunning()
+
+	if err != nil {
+		return err
+	}
+
+	if !isRunning {
+		if err := exec.Command("rc-service", openRCConfigurator.serviceName, "start").Run(); err != nil {
+			logDebugf("failed to start the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully started the service", nil)
+	} else {
+		logInfo("service is already running", nil)
+	}
+
+	return nil
+}
+
+// StopService stops the service.
+func (openRCConfigurator *OpenRCConfigurator) StopService() error {
+	openRCConfigurator.IsRunningAsRoot()
+
+	if !openRCConfigurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("stopping the service", nil)
+
+	isRunning, err := openRCConfigurator.IsServiceRunning()
+
+	if err != nil {
+		return err
+	}
+
+	if isRunning {
+		if err := exec.Command("rc-service", openRCConfigurator.serviceName, "stop").Run(); err != nil {
+			logDebugf("failed to stop the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully stopped the service", nil)
+	} else {
+		logInfo("service is already stopped", nil)
+	}
+
+	return nil
+}
+
+// RestartService restarts the service.
+func (openRCConfigurator *OpenRCConfigurator) RestartService() error {
+	openRCConfigurator.IsRunningAsRoot()
+
+	logInfo("restarting the service", nil)
+
+	if err := openRCConfigurator.StopService(); err != nil {
+		return err
+	}
+
+	if err := openRCConfigurator.StartService(); err != nil {
+		return err
+	}
+
+	logInfo("successfully restarted the service", nil)
+
+	return nil
+}
+
+// ReloadManager reloads the manager, OpenRC picks up the scripts without a reload.
+func (openRCConfigurator *OpenRCConfigurator) ReloadManager() error {
+	return nil
+}
+
+// IsRunningAsRoot checks if the application is running as root.
+func (openRCConfigurator *OpenRCConfigurator) IsRunningAsRoot() {
+	isRunningAsRoot()
+}
+
+// IsServiceExists checks if the service exists.
+func (openRCConfigurator *OpenRCConfigurator) IsServiceExists() bool {
+	return isServiceExists(openRCConfigurator.baseDirectory, openRCConfigurator.serviceName)
+}
+
+// IsServiceEnabled checks if the service is added to any runlevel.
+func (openRCConfigurator *OpenRCConfigurator) IsServiceEnabled() (bool, error) {
+	enabled, err := filepath.Glob(filepath.Join(openRCConfigurator.runlevelsDirectory, "*", openRCConfigurator.serviceName))
+	if err != nil {
+		return false, err
+	}
+
+	return len(enabled) > 0, nil
+}
+
+// IsServiceRunning checks if the service is running.
+func (openRCConfigurator *OpenRCConfigurator) IsServiceRunning() (bool, error) {
+	cmd := exec.Command("rc-service", openRCConfigurator.serviceName, "status")
+	logDebugf("executing command: %s", nil, cmd.String())
+
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+
+	// rc-service exits with a non-zero code when the service is stopped, stopping, inactive or crashed.
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return false, nil
+	}
+
+	return false, err
+}
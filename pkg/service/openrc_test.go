@@ -0,0 +1,112 @@
+package service
+
+import (
+	"github.com/darki73/goflaresync/pkg/helpers"
+	"github.com/darki73/goflaresync/pkg/log"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func init() {
+	log.SetOutput(io.Discard)
+}
+
+func TestOpenRCServiceTemplate(t *testing.T) {
+	configurator := newOpenRCConfigurator(t.TempDir())
+
+	template, err := configurator.GetServiceTemplate()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if !strings.HasPrefix(template, "#!/sbin/openrc-run\n") {
+		t.Errorf("Expected the script to be run by openrc-run but got '%s'", strings.SplitN(template, "\n", 2)[0])
+	}
+
+	for _, expected := range []string{
+		"supervisor=\"supervise-daemon\"",
+		"command_args=\"start\"",
+		"depend() {\n\tneed net\n",
+	} {
+		if !strings.Contains(template, expected) {
+			t.Errorf("Expected the script to contain '%s' but got '%s'", expected, template)
+		}
+	}
+}
+
+func TestOpenRCServicePaths(t *testing.T) {
+	rootDirectory := t.TempDir()
+	configurator := newOpenRCConfigurator(rootDirectory)
+
+	expected := filepath.Join(rootDirectory, "etc", "init.d", configurator.serviceName)
+	if configurator.GetServicePath() != expected {
+		t.Errorf("Expected '%s' but got '%s'", expected, configurator.GetServicePath())
+	}
+
+	if configurator.IsServiceExists() {
+		t.Errorf("Expected the service not to exist")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(expected), 0755); err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+
+	if err := os.WriteFile(expected, []byte("#!/sbin/openrc-run\n"), 0755); err != nil {
+		t.Fatalf("Failed to write the script: %v", err)
+	}
+
+	if !configurator.IsServiceExists() {
+		t.Errorf("Expected the service to exist")
+	}
+}
+
+func TestOpenRCServiceEnabled(t *testing.T) {
+	rootDirectory := t.TempDir()
+	configurator := newOpenRCConfigurator(rootDirectory)
+
+	if isEnabled, err := configurator.IsServiceEnabled(); err != nil || isEnabled {
+		t.Errorf("Expected the service not to be enabled but got %t (%v)", isEnabled, err)
+	}
+
+	runlevel := filepath.Join(rootDirectory, "etc", "runlevels", "default")
+	if err := os.MkdirAll(runlevel, 0755); err != nil {
+		t.Fatalf("Failed to create the runlevel: %v", err)
+	}
+
+	if err := os.Symlink(configurator.GetServicePath(), filepath.Join(runlevel, configurator.serviceName)); err != nil {
+		t.Fatalf("Failed to add the service to the runlevel: %v", err)
+	}
+
+	if isEnabled, err := configurator.IsServiceEnabled(); err != nil || !isEnabled {
+		t.Errorf("Expected the service to be enabled but got %t (%v)", isEnabled, err)
+	}
+}
+
+func TestOpenRCCreateService(t *testing.T) {
+	if !helpers.IsRoot() {
+		t.Skip("creating the service requires root")
+	}
+
+	rootDirectory := t.TempDir()
+	configurator := newOpenRCConfigurator(rootDirectory)
+
+	if err := os.MkdirAll(filepath.Join(rootDirectory, "etc", "init.d"), 0755); err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+
+	if err := configurator.CreateService(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	info, err := os.Stat(configurator.GetServicePath())
+	if err != nil {
+		t.Fatalf("Expected the script to be created but got %v", err)
+	}
+
+	if info.Mode().Perm()&0111 == 0 {
+		t.Errorf("Expected the script to be executable but got '%s'", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// RunitConfigurator is the runit configurator.
+type RunitConfigurator struct {
+	// baseDirectory is the base directory for service installation
+	baseDirectory string
+	// activeDirectory is the directory watched by runsvdir, the service is enabled by linking it there
+	activeDirectory string
+	// serviceName is the name of the service
+	serviceName string
+	// processIdentifierHandler is the process identifier handler
+	processIdentifierHandler *ProcessIdentifierHandler
+}
+
+// NewRunitConfigurator creates a new runit configurator.
+func NewRunitConfigurator() *RunitConfigurator {
+	return newRunitConfigurator("/")
+}
+
+// newRunitConfigurator creates a new runit configurator which manages the service below the given root directory.
+func newRunitConfigurator(rootDirectory string) *RunitConfigurator {
+	return &RunitConfigurator{
+		baseDirectory: path.Join(rootDirectory, "etc", "sv"),
+		activeDirectory: getFirstExistingDirectory([]string{
+			path.Join(rootDirectory, "var", "service"),
+			path.Join(rootDirectory, "etc", "service"),
+			path.Join(rootDirectory, "service"),
+		}),
+		serviceName:              applicationName(),
+		processIdentifierHandler: NewProcessIdentifierHandler(),
+	}
+}
+
+// GetServiceTemplate returns the service template, which is the run script of the service.
+func (runitConfigurator *RunitConfigurator) GetServiceTemplate() (string, error) {
+	executablePath, err := applicationFullPath()
+	if err != nil {
+		return "", err
+	}
+
+	runScript := []string{
+		"#!/bin/sh",
+		fmt.Sprintf("# %s/run", runitConfigurator.GetServicePath()),
+		"exec 2>&1",
+		fmt.Sprintf("exec %s start", executablePath),
+		"",
+	}
+
+	return combineSlices(runScript), nil
+}
+
+// GetLogTemplate returns the run script of the logger of the service.
+func (runitConfigurator *RunitConfigurator) GetLogTemplate() string {
+	logDirectory := fmt.Sprintf("/var/log/%s", runitConfigurator.serviceName)
+
+	logScript := []string{
+		"#!/bin/sh",
+		fmt.Sprintf("# %s/log/run", runitConfigurator.GetServicePath()),
+		fmt.Sprintf("mkdir -p %s", logDirectory),
+		fmt.Sprintf("exec svlogd -tt %s", logDirectory),
+		"",
+	}
+
+	return combineSlices(logScript)
+}
+
+// GetServicePath returns the service path.
+func (runitConfigurator *RunitConfigurator) GetServicePath() string {
+	return path.Join(runitConfigurator.baseDirectory, runitConfigurator.serviceName)
+}
+
+// GetActiveServicePath returns the path of the link to the service in the directory watched by runsvdir.
+func (runitConfigurator *RunitConfigurator) GetActiveServicePath() string {
+	return path.Join(runitConfigurator.activeDirectory, runitConfigurator.serviceName)
+}
+
+// GetProcessIdentifierHandler returns the process identifier handler.
+func (runitConfigurator *RunitConfigurator) GetProcessIdentifierHandler() *ProcessIdentifierHandler {
+	return runitConfigurator.processIdentifierHandler
+}
+
+// CreateService creates the service.
+func (runitConfigurator *RunitConfigurator) CreateService() error {
+	runitConfigurator.IsRunningAsRoot()
+
+	if runitConfigurator.IsServiceExists() {
+		logInfo("service already exists", nil)
+		return nil
+	}
+
+	logInfo("creating the service", nil)
+
+	template, err := runitConfigurator.GetServiceTemplate()
+
+	if err != nil {
+		return err
+	}
+
+	err = createSupervisedService(runitConfigurator.GetServicePath(), template, runitConfigurator.GetLogTemplate())
+
+	if err != nil {
+		logDebugf("failed to write service files: %s", nil, err.Error())
+		return err
+	}
+
+	logInfo("successfully created the service", nil)
+
+	return runitConfigurator.ReloadManager()
+}
+
+// DeleteService deletes the service.
+func (runitConfigurator *RunitConfigurator) DeleteService() error {
+	runitConfigurator.IsRunningAsRoot()
+
+	if !runitConfigurator.IsServiceExists() {
+		logInfo("service does not exist", nil)
+		return nil
+	}
+
+	logInfo("deleting the service", nil)
+
+	isRunning, err := runitConfigurator.IsServiceRunning()
+
+	if err != nil {
+		return err
+	}
+
+	if isRunning {
+		if err := runitConfigurator.StopService(); err != nil {
+			return err
+		}
+	}
+
+	if err := runitConfigurator.DisableService(); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(runitConfigurator.GetServicePath()); err != nil {
+		return err
+	}
+
+	logInfo("successfully deleted the service", nil)
+
+	return runitConfigurator.ReloadManager()
+}
+
+// EnableService enables the service by linking it into the directory watched by runsvdir.
+func (runitConfigurator *RunitConfigurator) EnableService() error {
+	runitConfigurator.IsRunningAsRoot()
+
+	if !runitConfigurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("enabling the service", nil)
+
+	isEnabled, err := runitConfigurator.IsServiceEnabled()
+	if err != nil {
+		return err
+	}
+
+	if !isEnabled {
+		if err := os.Symlink(runitConfigurator.GetServicePath(), runitConfigurator.GetActiveServicePath()); err != nil {
+			logDebugf("failed to enable the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully enabled the service", nil)
+	} else {
+		logInfo("service is already enabled", nil)
+	}
+
+	return nil
+}
+
+// DisableService disables the service by removing its link, runsvdir stops the service once the link is gone.
+func (runitConfigurator *RunitConfigurator) DisableService() error {
+	runitConfigurator.IsRunningAsRoot()
+
+	if !runitConfigurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("disabling the service", nil)
+
+	isEnabled, err := runitConfigurator.IsServiceEnabled()
+	if err != nil {
+		return err
+	}
+
+	if isEnabled {
+		if err := os.Remove(runitConfigurator.GetActiveServicePath()); err != nil {
+			logDebugf("failed to disable the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully disabled the service", nil)
+	} else {
+		logInfo("service is already disabled", nil)
+	}
+
+	return nil
+}
+
+// StartService starts the service.
+func (runitConfigurator *RunitConfigurator) StartService() error {
+	runitConfigurator.IsRunningAsRoot()
+
+	if !runitConfigurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	isEnabled, err := runitConfigurator.IsServiceEnabled()
+	if err != nil {
+		return err
+	}
+
+	if !isEnabled {
+		return fmt.Errorf("service is not enabled")
+	}
+
+	logInfo("starting the service", nil)
+
+	isRunning, err := runitConfigurator.IsServiceRunning()
+
+	if err != nil {
+		return err
+	}
+
+	if !isRunning {
+		if err := exec.Command("sv", "start", runitConfigurator.GetActiveServicePath()).Run(); err != nil {
+			logDebugf("failed to start the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully started the service", nil)
+	} else {
+		logInfo("service is already running", nil)
+	}
+
+	return nil
+}
+
+// StopService stops the service, the application is given enough time to finish the in-flight update.
+func (runitConfigurator *RunitConfigurator) StopService() error {
+	runitConfigurator.IsRunningAsRoot()
+
+	if !runitConfigurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("stopping the service", nil)
+
+	isRunning, err := runitConfigurator.IsServiceRunning()
+
+	if err != nil {
+		return err
+	}
+
+	if isRunning {
+		if err := exec.Command("sv", "-w", "35", "stop", runitConfigurator.GetActiveServicePath()).Run(); err != nil {
+			logDebugf("failed to stop the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully stopped the service", nil)
+	} else {
+		logInfo("service is already stopped", nil)
+	}
+
+	return nil
+}
+
+// RestartService restarts the service.
+func (runitConfigurator *RunitConfigurator) RestartService() error {
+	runitConfigurator.IsRunningAsRoot()
+
+	logInfo("restarting the service", nil)
+
+	if err := runitConfigurator.StopService(); err != nil {
+		return err
+	}
+
+	if err := runitConfigurator.StartService(); err != nil {
+		return err
+	}
+
+	logInfo("successfully restarted the service", nil)
+
+	return nil
+}
+
+// ReloadManager reloads the manager, runsvdir picks up the changes of the watched directory without a reload.
+func (runitConfigurator *RunitConfigurator) ReloadManager() error {
+	return nil
+}
+
+// IsRunningAsRoot checks if the application is running as root.
+func (runitConfigurator *RunitConfigurator) IsRunningAsRoot() {
+	isRunningAsRoot()
+}
+
+// IsServiceExists checks if the service exists.
+func (runitConfigurator *RunitConfigurator) IsServiceExists() bool {
+	return isServiceExists(runitConfigurator.baseDirectory, runitConfigurator.serviceName)
+}
+
+// IsServiceEnabled checks if the service is linked into the directory watched by runsvdir.
+func (runitConfigurator *RunitConfigurator) IsServiceEnabled() (bool, error) {
+	return isSymbolicLinkExists(runitConfigurator.GetActiveServicePath()), nil
+}
+
+// IsServiceRunning checks if the service is running.
+func (runitConfigurator *RunitConfigurator) IsServiceRunning() (bool, error) {
+	isEnabled, err := runitConfigurator.IsServiceEnabled()
+	if err != nil || !isEnabled {
+		return false, err
+	}
+
+	cmd := exec.Command("sv", "status", runitConfigurator.GetActiveServicePath())
+	logDebugf("executing command: %s", nil, cmd.String())
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	_ = cmd.Run()
+
+	return parseRunitStatus(out.String())
+}
+
+// parseRunitStatus parses the output of `sv status`, for example `run: /var/service/goflaresync: (pid 123) 45s; run: log: (pid 122) 45s`.
+func parseRunitStatus(output string) (bool, error) {
+	switch {
+	case strings.HasPrefix(output, "run: "):
+		return true, nil
+	case strings.HasPrefix(output, "down: "), strings.HasPrefix(output, "finish: "):
+		return false, nil
+	case strings.HasPrefix(output, "warning: "), strings.HasPrefix(output, "fail: "):
+		// The supervisor of the service is not running yet.
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected sv output: %s", strings.TrimSpace(output))
+	}
+}
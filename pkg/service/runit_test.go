@@ -0,0 +1,116 @@
+package service
+
+import (
+	"github.com/darki73/goflaresync/pkg/helpers"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunitServiceTemplates(t *testing.T) {
+	configurator := newRunitConfigurator(t.TempDir())
+
+	template, err := configurator.GetServiceTemplate()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if !strings.HasPrefix(template, "#!/bin/sh\n") || !strings.Contains(template, "exec 2>&1\nexec ") || !strings.HasSuffix(template, " start\n") {
+		t.Errorf("Expected the run script to start the application but got '%s'", template)
+	}
+
+	if logTemplate := configurator.GetLogTemplate(); !strings.Contains(logTemplate, "exec svlogd -tt /var/log/") {
+		t.Errorf("Expected the log run script to use svlogd but got '%s'", logTemplate)
+	}
+}
+
+func TestRunitServicePaths(t *testing.T) {
+	rootDirectory := t.TempDir()
+
+	if configurator := newRunitConfigurator(rootDirectory); configurator.activeDirectory != filepath.Join(rootDirectory, "var", "service") {
+		t.Errorf("Expected '%s' but got '%s'", filepath.Join(rootDirectory, "var", "service"), configurator.activeDirectory)
+	}
+
+	if err := os.MkdirAll(filepath.Join(rootDirectory, "etc", "service"), 0755); err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+
+	configurator := newRunitConfigurator(rootDirectory)
+
+	expected := filepath.Join(rootDirectory, "etc", "sv", configurator.serviceName)
+	if configurator.GetServicePath() != expected {
+		t.Errorf("Expected '%s' but got '%s'", expected, configurator.GetServicePath())
+	}
+
+	expected = filepath.Join(rootDirectory, "etc", "service", configurator.serviceName)
+	if configurator.GetActiveServicePath() != expected {
+		t.Errorf("Expected '%s' but got '%s'", expected, configurator.GetActiveServicePath())
+	}
+}
+
+func TestParseRunitStatus(t *testing.T) {
+	tests := []struct {
+		output   string
+		running  bool
+		hasError bool
+	}{
+		{output: "run: /var/service/goflaresync: (pid 123) 45s; run: log: (pid 122) 45s\n", running: true},
+		{output: "down: /var/service/goflaresync: 3s, normally up; run: log: (pid 122) 45s\n", running: false},
+		{output: "finish: /var/service/goflaresync: (pid 123) 1s\n", running: false},
+		{output: "warning: /var/service/goflaresync: unable to open supervise/ok: file does not exist\n", running: false},
+		{output: "", hasError: true},
+	}
+
+	for _, test := range tests {
+		running, err := parseRunitStatus(test.output)
+
+		if (err != nil) != test.hasError {
+			t.Errorf("Expected error to be %t for '%s' but got %v", test.hasError, test.output, err)
+		}
+
+		if running != test.running {
+			t.Errorf("Expected %t for '%s' but got %t", test.running, test.output, running)
+		}
+	}
+}
+
+func TestRunitCreateAndEnableService(t *testing.T) {
+	if !helpers.IsRoot() {
+		t.Skip("creating the service requires root")
+	}
+
+	rootDirectory := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(rootDirectory, "var", "service"), 0755); err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+
+	configurator := newRunitConfigurator(rootDirectory)
+
+	if err := configurator.CreateService(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	for _, script := range []string{"run", filepath.Join("log", "run")} {
+		info, err := os.Stat(filepath.Join(configurator.GetServicePath(), script))
+		if err != nil {
+			t.Fatalf("Expected `%s` to be created but got %v", script, err)
+		}
+
+		if info.Mode().Perm()&0111 == 0 {
+			t.Errorf("Expected `%s` to be executable but got '%s'", script, info.Mode().Perm())
+		}
+	}
+
+	if err := configurator.EnableService(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if target, err := os.Readlink(configurator.GetActiveServicePath()); err != nil || target != configurator.GetServicePath() {
+		t.Errorf("Expected the service to be linked to '%s' but got '%s' (%v)", configurator.GetServicePath(), target, err)
+	}
+
+	if isEnabled, _ := configurator.IsServiceEnabled(); !isEnabled {
+		t.Errorf("Expected the service to be enabled")
+	}
+}
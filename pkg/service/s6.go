@@ -0,0 +1,358 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// S6Configurator is the s6 configurator.
+type S6Configurator struct {
+	// baseDirectory is the base directory for service installation
+	baseDirectory string
+	// activeDirectory is the scan directory watched by s6-svscan, the service is enabled by linking it there
+	activeDirectory string
+	// serviceName is the name of the service
+	serviceName string
+	// processIdentifierHandler is the process identifier handler
+	processIdentifierHandler *ProcessIdentifierHandler
+}
+
+// NewS6Configurator creates a new s6 configurator.
+func NewS6Configurator() *S6Configurator {
+	return newS6Configurator("/")
+}
+
+// newS6Configurator creates a new s6 configurator which manages the service below the given root directory.
+func newS6Configurator(rootDirectory string) *S6Configurator {
+	return &S6Configurator{
+		baseDirectory: path.Join(rootDirectory, "etc", "s6", "sv"),
+		activeDirectory: getFirstExistingDirectory([]string{
+			path.Join(rootDirectory, "run", "service"),
+			path.Join(rootDirectory, "service"),
+		}),
+		serviceName:              applicationName(),
+		processIdentifierHandler: NewProcessIdentifierHandler(),
+	}
+}
+
+// GetServiceTemplate returns the service template, which is the run script of the service.
+func (s6Configurator *S6Configurator) GetServiceTemplate() (string, error) {
+	executablePath, err := applicationFullPath()
+	if err != nil {
+		return "", err
+	}
+
+	runScript := []string{
+		"#!/bin/sh",
+		fmt.Sprintf("# %s/run", s6Configurator.GetServicePath()),
+		"exec 2>&1",
+		fmt.Sprintf("exec %s start", executablePath),
+		"",
+	}
+
+	return combineSlices(runScript), nil
+}
+
+// GetLogTemplate returns the run script of the logger of the service.
+func (s6Configurator *S6Configurator) GetLogTemplate() string {
+	logDirectory := fmt.Sprintf("/var/log/%s", s6Configurator.serviceName)
+
+	logScript := []string{
+		"#!/bin/sh",
+		fmt.Sprintf("# %s/log/run", s6Configurator.GetServicePath()),
+		fmt.Sprintf("mkdir -p %s", logDirectory),
+		fmt.Sprintf("exec s6-log -b n10 s1000000 T %s", logDirectory),
+		"",
+	}
+
+	return combineSlices(logScript)
+}
+
+// GetServicePath returns the service path.
+func (s6Configurator *S6Configurator) GetServicePath() string {
+	return path.Join(s6Configurator.baseDirectory, s6Configurator.serviceName)
+}
+
+// GetActiveServicePath returns the path of the link to the service in the scan directory.
+func (s6Configurator *S6Configurator) GetActiveServicePath() string {
+	return path.Join(s6Configurator.activeDirectory, s6Configurator.serviceName)
+}
+
+// GetProcessIdentifierHandler returns the process identifier handler.
+func (s6Configurator *S6Configurator) GetProcessIdentifierHandler() *ProcessIdentifierHandler {
+	return s6Configurator.processIdentifierHandler
+}
+
+// CreateService creates the service.
+func (s6Configurator *S6Configurator) CreateService() error {
+	s6Configurator.IsRunningAsRoot()
+
+	if s6Configurator.IsServiceExists() {
+		logInfo("service already exists", nil)
+		return nil
+	}
+
+	logInfo("creating the service", nil)
+
+	template, err := s6Configurator.GetServiceTemplate()
+
+	if err != nil {
+		return err
+	}
+
+	err = createSupervisedService(s6Configurator.GetServicePath(), template, s6Configurator.GetLogTemplate())
+
+	if err != nil {
+		logDebugf("failed to write service files: %s", nil, err.Error())
+		return err
+	}
+
+	logInfo("successfully created the service", nil)
+
+	return nil
+}
+
+// DeleteService deletes the service.
+func (s6Configurator *S6Configurator) DeleteService() error {
+	s6Configurator.IsRunningAsRoot()
+
+	if !s6Configurator.IsServiceExists() {
+		logInfo("service does not exist", nil)
+		return nil
+	}
+
+	logInfo("deleting the service", nil)
+
+	isRunning, err := s6Configurator.IsServiceRunning()
+
+	if err != nil {
+		return err
+	}
+
+	if isRunning {
+		if err := s6Configurator.StopService(); err != nil {
+			return err
+		}
+	}
+
+	if err := s6Configurator.DisableService(); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(s6Configurator.GetServicePath()); err != nil {
+		return err
+	}
+
+	logInfo("successfully deleted the service", nil)
+
+	return nil
+}
+
+// EnableService enables the service by linking it into the scan directory.
+func (s6Configurator *S6Configurator) EnableService() error {
+	s6Configurator.IsRunningAsRoot()
+
+	if !s6Configurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("enabling the service", nil)
+
+	isEnabled, err := s6Configurator.IsServiceEnabled()
+	if err != nil {
+		return err
+	}
+
+	if !isEnabled {
+		if err := os.Symlink(s6Configurator.GetServicePath(), s6Configurator.GetActiveServicePath()); err != nil {
+			logDebugf("failed to enable the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully enabled the service", nil)
+	} else {
+		logInfo("service is already enabled", nil)
+		return nil
+	}
+
+	return s6Configurator.ReloadManager()
+}
+
+// DisableService disables the service by removing its link, s6-svscan stops the service once it rescans the directory.
+func (s6Configurator *S6Configurator) DisableService() error {
+	s6Configurator.IsRunningAsRoot()
+
+	if !s6Configurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("disabling the service", nil)
+
+	isEnabled, err := s6Configurator.IsServiceEnabled()
+	if err != nil {
+		return err
+	}
+
+	if isEnabled {
+		if err := os.Remove(s6Configurator.GetActiveServicePath()); err != nil {
+			logDebugf("failed to disable the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully disabled the service", nil)
+	} else {
+		logInfo("service is already disabled", nil)
+		return nil
+	}
+
+	return s6Configurator.ReloadManager()
+}
+
+// StartService starts the service.
+func (s6Configurator *S6Configurator) StartService() error {
+	s6Configurator.IsRunningAsRoot()
+
+	if !s6Configurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	isEnabled, err := s6Configurator.IsServiceEnabled()
+	if err != nil {
+		return err
+	}
+
+	if !isEnabled {
+		return fmt.Errorf("service is not enabled")
+	}
+
+	logInfo("starting the service", nil)
+
+	isRunning, err := s6Configurator.IsServiceRunning()
+
+	if err != nil {
+		return err
+	}
+
+	if !isRunning {
+		if err := exec.Command("s6-svc", "-u", s6Configurator.GetActiveServicePath()).Run(); err != nil {
+			logDebugf("failed to start the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully started the service", nil)
+	} else {
+		logInfo("service is already running", nil)
+	}
+
+	return nil
+}
+
+// StopService stops the service, the application is given enough time to finish the in-flight update.
+func (s6Configurator *S6Configurator) StopService() error {
+	s6Configurator.IsRunningAsRoot()
+
+	if !s6Configurator.IsServiceExists() {
+		return fmt.Errorf("service does not exist")
+	}
+
+	logInfo("stopping the service", nil)
+
+	isRunning, err := s6Configurator.IsServiceRunning()
+
+	if err != nil {
+		return err
+	}
+
+	if isRunning {
+		if err := exec.Command("s6-svc", "-wd", "-T", "35000", "-d", s6Configurator.GetActiveServicePath()).Run(); err != nil {
+			logDebugf("failed to stop the service: %s", nil, err.Error())
+			return err
+		}
+		logInfo("successfully stopped the service", nil)
+	} else {
+		logInfo("service is already stopped", nil)
+	}
+
+	return nil
+}
+
+// RestartService restarts the service.
+func (s6Configurator *S6Configurator) RestartService() error {
+	s6Configurator.IsRunningAsRoot()
+
+	logInfo("restarting the service", nil)
+
+	if err := s6Configurator.StopService(); err != nil {
+		return err
+	}
+
+	if err := s6Configurator.StartService(); err != nil {
+		return err
+	}
+
+	logInfo("successfully restarted the service", nil)
+
+	return nil
+}
+
+// ReloadManager makes s6-svscan rescan the scan directory and stop the services which were removed from it.
+func (s6Configurator *S6Configurator) ReloadManager() error {
+	logInfo("reloading the manager", nil)
+
+	if err := exec.Command("s6-svscanctl", "-an", s6Configurator.activeDirectory).Run(); err != nil {
+		logDebugf("failed to rescan the scan directory: %s", nil, err.Error())
+		return err
+	}
+
+	logInfo("successfully reloaded the manager", nil)
+
+	return nil
+}
+
+// IsRunningAsRoot checks if the application is running as root.
+func (s6Configurator *S6Configurator) IsRunningAsRoot() {
+	isRunningAsRoot()
+}
+
+// IsServiceExists checks if the service exists.
+func (s6Configurator *S6Configurator) IsServiceExists() bool {
+	return isServiceExists(s6Configurator.baseDirectory, s6Configurator.serviceName)
+}
+
+// IsServiceEnabled checks if the service is linked into the scan directory.
+func (s6Configurator *S6Configurator) IsServiceEnabled() (bool, error) {
+	return isSymbolicLinkExists(s6Configurator.GetActiveServicePath()), nil
+}
+
+// IsServiceRunning checks if the service is running.
+func (s6Configurator *S6Configurator) IsServiceRunning() (bool, error) {
+	isEnabled, err := s6Configurator.IsServiceEnabled()
+	if err != nil || !isEnabled {
+		return false, err
+	}
+
+	cmd := exec.Command("s6-svstat", s6Configurator.GetActiveServicePath())
+	logDebugf("executing command: %s", nil, cmd.String())
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	if err := cmd.Run(); err != nil && out.Len() == 0 {
+		// The supervisor of the service is not running yet.
+		return false, nil
+	}
+
+	return parseS6Status(out.String())
+}
+
+// parseS6Status parses the output of `s6-svstat`, for example `up (pid 123) 45 seconds`.
+func parseS6Status(output string) (bool, error) {
+	switch {
+	case strings.HasPrefix(output, "up "):
+		return true, nil
+	case strings.HasPrefix(output, "down "):
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected s6-svstat output: %s", strings.TrimSpace(output))
+	}
+}
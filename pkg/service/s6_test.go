@@ -0,0 +1,81 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestS6ServiceTemplates(t *testing.T) {
+	configurator := newS6Configurator(t.TempDir())
+
+	template, err := configurator.GetServiceTemplate()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if !strings.HasPrefix(template, "#!/bin/sh\n") || !strings.HasSuffix(template, " start\n") {
+		t.Errorf("Expected the run script to start the application but got '%s'", template)
+	}
+
+	if logTemplate := configurator.GetLogTemplate(); !strings.Contains(logTemplate, "exec s6-log ") {
+		t.Errorf("Expected the log run script to use s6-log but got '%s'", logTemplate)
+	}
+}
+
+func TestS6ServicePaths(t *testing.T) {
+	rootDirectory := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(rootDirectory, "service"), 0755); err != nil {
+		t.Fatalf("Failed to create the directory: %v", err)
+	}
+
+	configurator := newS6Configurator(rootDirectory)
+
+	expected := filepath.Join(rootDirectory, "etc", "s6", "sv", configurator.serviceName)
+	if configurator.GetServicePath() != expected {
+		t.Errorf("Expected '%s' but got '%s'", expected, configurator.GetServicePath())
+	}
+
+	expected = filepath.Join(rootDirectory, "service", configurator.serviceName)
+	if configurator.GetActiveServicePath() != expected {
+		t.Errorf("Expected '%s' but got '%s'", expected, configurator.GetActiveServicePath())
+	}
+
+	if isEnabled, _ := configurator.IsServiceEnabled(); isEnabled {
+		t.Errorf("Expected the service not to be enabled")
+	}
+
+	if err := os.Symlink(configurator.GetServicePath(), configurator.GetActiveServicePath()); err != nil {
+		t.Fatalf("Failed to link the service: %v", err)
+	}
+
+	if isEnabled, _ := configurator.IsServiceEnabled(); !isEnabled {
+		t.Errorf("Expected the service to be enabled even if the service directory does not exist")
+	}
+}
+
+func TestParseS6Status(t *testing.T) {
+	tests := []struct {
+		output   string
+		running  bool
+		hasError bool
+	}{
+		{output: "up (pid 123) 45 seconds\n", running: true},
+		{output: "up (pid 123) 45 seconds, normally down, ready 44 seconds\n", running: true},
+		{output: "down (exitcode 0) 3 seconds, normally up, ready 3 seconds\n", running: false},
+		{output: "unexpected\n", hasError: true},
+	}
+
+	for _, test := range tests {
+		running, err := parseS6Status(test.output)
+
+		if (err != nil) != test.hasError {
+			t.Errorf("Expected error to be %t for '%s' but got %v", test.hasError, test.output, err)
+		}
+
+		if running != test.running {
+			t.Errorf("Expected %t for '%s' but got %t", test.running, test.output, running)
+		}
+	}
+}
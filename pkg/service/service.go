@@ -14,11 +14,15 @@ const (
 	Upstart  InitSystem = "upstart"
 	SysVinit InitSystem = "sysvinit"
 	OpenRC   InitSystem = "openrc"
+	Runit    InitSystem = "runit"
+	S6       InitSystem = "s6"
 	None     InitSystem = "none"
 )
 
 // Manager is the service manager.
 type Manager struct {
+	// initSystem is the detected initialization system
+	initSystem InitSystem
 	// systemManager is the system manager
 	systemManager InitializationSystemManager
 }
@@ -36,6 +40,11 @@ func (manager *Manager) GetSystemManager() InitializationSystemManager {
 	return manager.systemManager
 }
 
+// GetInitSystem returns the detected initialization system.
+func (manager *Manager) GetInitSystem() InitSystem {
+	return manager.initSystem
+}
+
 // HasSystemManager checks if the system manager is available.
 func (manager *Manager) HasSystemManager() bool {
 	return manager.systemManager != nil
@@ -43,7 +52,9 @@ func (manager *Manager) HasSystemManager() bool {
 
 // bootstrap bootstraps the service manager.
 func (manager *Manager) bootstrap() *Manager {
-	switch manager.detectInitializationSystem() {
+	manager.initSystem = manager.detectInitializationSystem()
+
+	switch manager.initSystem {
 	case Systemd:
 		manager.systemManager = NewSystemdConfigurator()
 		break
@@ -53,9 +64,15 @@ func (manager *Manager) bootstrap() *Manager {
 	case SysVinit:
 		manager.systemManager = NewSysVInitConfigurator()
 		break
-	//case OpenRC:
-	//	manager.systemManager = NewOpenRCConfigurator()
-	//	break
+	case OpenRC:
+		manager.systemManager = NewOpenRCConfigurator()
+		break
+	case Runit:
+		manager.systemManager = NewRunitConfigurator()
+		break
+	case S6:
+		manager.systemManager = NewS6Configurator()
+		break
 	case None:
 		manager.systemManager = nil
 		break
@@ -81,14 +98,23 @@ func (manager *Manager) detectInitializationSystem() InitSystem {
 		return Upstart
 	}
 
-	if manager.isCommandAvailable("service") && manager.isDirectoryExists("/etc/init.d") {
-		return SysVinit
-	}
-
+	// OpenRC also uses /etc/init.d, so it has to be detected before SysVinit.
 	if manager.isCommandAvailable("rc-service") || manager.isCommandAvailable("rc-update") {
 		return OpenRC
 	}
 
+	if manager.isCommandAvailable("sv") && manager.isDirectoryExists("/etc/sv") {
+		return Runit
+	}
+
+	if manager.isCommandAvailable("s6-svscan") && manager.isCommandAvailable("s6-svc") {
+		return S6
+	}
+
+	if manager.isCommandAvailable("service") && manager.isDirectoryExists("/etc/init.d") {
+		return SysVinit
+	}
+
 	return None
 }
 
@@ -0,0 +1,66 @@
+package service
+
+// Status is the status of the service.
+type Status struct {
+	// InitSystem is the detected initialization system
+	InitSystem InitSystem
+	// ServicePath is the path to the unit file or the init script, empty if the initialization system is not supported
+	ServicePath string
+	// Installed is a flag indicating whether the service is installed
+	Installed bool
+	// Enabled is a flag indicating whether the service is enabled to start on boot
+	Enabled bool
+	// Running is a flag indicating whether the service is running
+	Running bool
+	// ProcessIdentifier is the process identifier of the running application, 0 if it is not running
+	ProcessIdentifier int
+}
+
+// GetStatus returns the status of the service.
+// An error is returned if the initialization system could not tell whether the service is enabled or running.
+func (manager *Manager) GetStatus() (*Status, error) {
+	status := &Status{
+		InitSystem: manager.GetInitSystem(),
+	}
+
+	if !manager.HasSystemManager() {
+		return status, nil
+	}
+
+	systemManager := manager.GetSystemManager()
+	status.ServicePath = systemManager.GetServicePath()
+	status.ProcessIdentifier = getRunningProcessIdentifier(systemManager.GetProcessIdentifierHandler())
+	status.Installed = systemManager.IsServiceExists()
+
+	if !status.Installed {
+		return status, nil
+	}
+
+	isEnabled, err := systemManager.IsServiceEnabled()
+	if err != nil {
+		return status, err
+	}
+	status.Enabled = isEnabled
+
+	isRunning, err := systemManager.IsServiceRunning()
+	if err != nil {
+		return status, err
+	}
+	status.Running = isRunning
+
+	return status, nil
+}
+
+// getRunningProcessIdentifier returns the process identifier from the process identifier file, 0 if the process is not running.
+func getRunningProcessIdentifier(handler *ProcessIdentifierHandler) int {
+	if !handler.IsSupported() || !handler.IsProcessIdentifierFileExists() {
+		return 0
+	}
+
+	processIdentifier, err := handler.ReadProcessIdentifierFromFile()
+	if err != nil || !handler.IsProcessRunning(processIdentifier) {
+		return 0
+	}
+
+	return processIdentifier
+}
@@ -0,0 +1,262 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State is the definition of the state persisted between the synchronizations.
+type State struct {
+	// Addresses is the last published address for every network.
+	Addresses map[string]string `json:"addresses"`
+	// ProviderRecords is the list of records the monitored records resolved to, keyed by the name of the provider instance.
+	ProviderRecords map[string][]*entities.Record `json:"provider_records"`
+	// LastSync is the time of the last successful synchronization.
+	LastSync time.Time `json:"last_sync"`
+	// LastReconciliation is the time of the last successful synchronization which looked up all records.
+	LastReconciliation time.Time `json:"last_reconciliation"`
+	// LastAttempt is the time of the last synchronization, successful or not.
+	LastAttempt time.Time `json:"last_attempt"`
+	// LastError is the error of the last synchronization, empty if it was successful.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Store is the definition of the on-disk state store.
+type Store struct {
+	// path is the path to the state file, the state is not persisted when it is empty.
+	path string
+	// state is the current state.
+	state *State
+	// mutex guards the state.
+	mutex sync.RWMutex
+}
+
+// NewStore returns a new state store backed by the given file.
+func NewStore(path string) *Store {
+	return &Store{
+		path:  path,
+		state: newState(),
+	}
+}
+
+// GetPath returns the path to the state file.
+func (store *Store) GetPath() string {
+	return store.path
+}
+
+// Load loads the state from the state file.
+// A missing state file is not an error, the state is simply empty.
+func (store *Store) Load() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.state = newState()
+
+	if store.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	state := newState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return err
+	}
+
+	if state.Addresses == nil {
+		state.Addresses = make(map[string]string)
+	}
+
+	if state.ProviderRecords == nil {
+		state.ProviderRecords = make(map[string][]*entities.Record)
+	}
+
+	store.state = state
+
+	return nil
+}
+
+// Save atomically writes the state to the state file.
+// The state file is readable by everyone, so that monitoring agents can check it without running as root.
+func (store *Store) Save() error {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	if store.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(store.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.path), 0755); err != nil {
+		return err
+	}
+
+	temporaryFile, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporaryFile.Name())
+
+	if _, err := temporaryFile.Write(data); err != nil {
+		_ = temporaryFile.Close()
+		return err
+	}
+
+	if err := temporaryFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(temporaryFile.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryFile.Name(), store.path)
+}
+
+// GetAddress returns the last published address for the given network.
+func (store *Store) GetAddress(network string) string {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.state.Addresses[network]
+}
+
+// SetAddress sets the last published address for the given network.
+func (store *Store) SetAddress(network string, address string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.state.Addresses[network] = address
+}
+
+// GetRecords returns copies of the known records of the provider instance with the given type and name.
+func (store *Store) GetRecords(providerName string, recordType string, name string) []*entities.Record {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var matching []*entities.Record
+
+	for _, record := range store.state.ProviderRecords[providerName] {
+		if record.Type == recordType && record.Name == name {
+			copied := *record
+			matching = append(matching, &copied)
+		}
+	}
+
+	return matching
+}
+
+// SetRecords replaces the known records of every provider instance.
+func (store *Store) SetRecords(records map[string][]*entities.Record) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.state.ProviderRecords = make(map[string][]*entities.Record)
+	for providerName, providerRecords := range records {
+		store.state.ProviderRecords[providerName] = providerRecords
+	}
+}
+
+// PutRecord adds the record of the provider instance or replaces the known record with the same identifier.
+func (store *Store) PutRecord(providerName string, record *entities.Record) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	copied := *record
+
+	for index, known := range store.state.ProviderRecords[providerName] {
+		if known.ID == record.ID {
+			store.state.ProviderRecords[providerName][index] = &copied
+			return
+		}
+	}
+
+	store.state.ProviderRecords[providerName] = append(store.state.ProviderRecords[providerName], &copied)
+}
+
+// GetLastSync returns the time of the last successful synchronization.
+func (store *Store) GetLastSync() time.Time {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.state.LastSync
+}
+
+// SetLastSync sets the time of the last successful synchronization.
+func (store *Store) SetLastSync(lastSync time.Time) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.state.LastSync = lastSync
+}
+
+// GetLastReconciliation returns the time of the last successful synchronization which looked up all records.
+func (store *Store) GetLastReconciliation() time.Time {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.state.LastReconciliation
+}
+
+// SetLastReconciliation sets the time of the last successful synchronization which looked up all records.
+func (store *Store) SetLastReconciliation(lastReconciliation time.Time) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.state.LastReconciliation = lastReconciliation
+}
+
+// GetLastAttempt returns the time of the last synchronization, successful or not.
+func (store *Store) GetLastAttempt() time.Time {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.state.LastAttempt
+}
+
+// SetLastAttempt sets the time of the last synchronization, successful or not.
+func (store *Store) SetLastAttempt(lastAttempt time.Time) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.state.LastAttempt = lastAttempt
+}
+
+// GetLastError returns the error of the last synchronization, empty if it was successful.
+func (store *Store) GetLastError() string {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.state.LastError
+}
+
+// SetLastError sets the error of the last synchronization, empty if it was successful.
+func (store *Store) SetLastError(lastError string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.state.LastError = lastError
+}
+
+// newState returns a new empty state.
+func newState() *State {
+	return &State{
+		Addresses:       make(map[string]string),
+		ProviderRecords: make(map[string][]*entities.Record),
+	}
+}
@@ -0,0 +1,107 @@
+package state
+
+import (
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "state.json"))
+
+	if err := store.Load(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if address := store.GetAddress("tcp4"); address != "" {
+		t.Errorf("Expected empty address but got '%s'", address)
+	}
+
+	if !store.GetLastSync().IsZero() {
+		t.Errorf("Expected zero last sync but got '%s'", store.GetLastSync())
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	lastSync := time.Date(2023, 7, 13, 12, 0, 0, 0, time.UTC)
+
+	store := NewStore(path)
+	store.SetAddress("tcp4", "198.51.100.7")
+	store.SetRecords(map[string][]*entities.Record{
+		"cloudflare": {
+			{ID: "record-1", ZoneID: "zone-1", ZoneName: "example.com", Name: "example.com", Type: "A", Content: "198.51.100.7"},
+		},
+	})
+	store.SetLastSync(lastSync)
+	store.SetLastReconciliation(lastSync)
+	store.SetLastAttempt(lastSync.Add(time.Minute))
+	store.SetLastError("record `example.com`: timeout")
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat state file: %v", err)
+		}
+		if info.Mode().Perm() != 0644 {
+			t.Errorf("Expected '%s' but got '%s'", os.FileMode(0644), info.Mode().Perm())
+		}
+	}
+
+	loaded := NewStore(path)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	if address := loaded.GetAddress("tcp4"); address != "198.51.100.7" {
+		t.Errorf("Expected '198.51.100.7' but got '%s'", address)
+	}
+
+	records := loaded.GetRecords("cloudflare", "A", "example.com")
+	if len(records) != 1 || records[0].ID != "record-1" || records[0].ZoneID != "zone-1" {
+		t.Errorf("Expected record 'record-1' in zone 'zone-1' but got %+v", records)
+	}
+
+	if !loaded.GetLastSync().Equal(lastSync) {
+		t.Errorf("Expected '%s' but got '%s'", lastSync, loaded.GetLastSync())
+	}
+
+	if !loaded.GetLastAttempt().Equal(lastSync.Add(time.Minute)) {
+		t.Errorf("Expected '%s' but got '%s'", lastSync.Add(time.Minute), loaded.GetLastAttempt())
+	}
+
+	if loaded.GetLastError() != "record `example.com`: timeout" {
+		t.Errorf("Expected 'record `example.com`: timeout' but got '%s'", loaded.GetLastError())
+	}
+
+	if !loaded.GetLastReconciliation().Equal(lastSync) {
+		t.Errorf("Expected '%s' but got '%s'", lastSync, loaded.GetLastReconciliation())
+	}
+}
+
+func TestPutRecord(t *testing.T) {
+	store := NewStore("")
+	store.PutRecord("cloudflare", &entities.Record{ID: "record-1", Name: "example.com", Type: "A", Content: "198.51.100.7"})
+	store.PutRecord("cloudflare", &entities.Record{ID: "record-1", Name: "example.com", Type: "A", Content: "198.51.100.8"})
+	store.PutRecord("internal", &entities.Record{ID: "record-1", Name: "example.com", Type: "A", Content: "10.0.0.1"})
+
+	records := store.GetRecords("cloudflare", "A", "example.com")
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record but got %d", len(records))
+	}
+
+	if records[0].Content != "198.51.100.8" {
+		t.Errorf("Expected '198.51.100.8' but got '%s'", records[0].Content)
+	}
+
+	if err := store.Save(); err != nil {
+		t.Errorf("Expected no error for store without path but got %v", err)
+	}
+}
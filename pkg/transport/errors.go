@@ -0,0 +1,9 @@
+package transport
+
+import "errors"
+
+var (
+	ErrInvalidProxy             = errors.New("invalid proxy")
+	ErrInvalidCABundle          = errors.New("invalid CA bundle")
+	ErrInvalidClientCertificate = errors.New("invalid client certificate")
+)
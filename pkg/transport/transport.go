@@ -0,0 +1,77 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	transportConfiguration "github.com/darki73/goflaresync/pkg/configuration/transport"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// NewFromConfiguration returns a new HTTP transport based on the HTTP configuration.
+func NewFromConfiguration() (*http.Transport, error) {
+	return New(configuration.GetConfiguration().GetHTTP())
+}
+
+// New returns a new HTTP transport which uses the configured proxy, certificate authorities and client certificate.
+func New(config *transportConfiguration.Configuration) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.GetProxy() != "" {
+		proxy, err := url.Parse(config.GetProxy())
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidProxy, config.GetProxy())
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if config.GetCAFile() == "" && config.GetClientCertificate() == "" {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if config.GetCAFile() != "" {
+		pool, err := loadCertificatePool(config.GetCAFile())
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.GetClientCertificate() != "" {
+		certificate, err := tls.LoadX509KeyPair(config.GetClientCertificate(), config.GetClientKey())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidClientCertificate, err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// loadCertificatePool returns the system certificate pool extended with the certificates of the given PEM bundle.
+func loadCertificatePool(path string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	bundle, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCABundle, err.Error())
+	}
+
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("%w: no certificates found in `%s`", ErrInvalidCABundle, path)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"encoding/pem"
+	"errors"
+	transportConfiguration "github.com/darki73/goflaresync/pkg/configuration/transport"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewTrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("192.0.2.1"))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, bundle, 0600); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+
+	untrusted, err := New(transportConfiguration.InitializeWithDefaults())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if _, err := (&http.Client{Transport: untrusted}).Get(server.URL); err == nil {
+		t.Errorf("Expected the certificate of the server not to be trusted")
+	}
+
+	trusted, err := New(&transportConfiguration.Configuration{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	response, err := (&http.Client{Transport: trusted}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the certificate of the server to be trusted but got %v", err)
+	}
+	_ = response.Body.Close()
+}
+
+func TestNewUsesProxy(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		proxied = request.URL.Host == "api.example.com"
+		_, _ = writer.Write([]byte(`{"success":true}`))
+	}))
+	defer proxy.Close()
+
+	proxyTransport, err := New(&transportConfiguration.Configuration{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	response, err := (&http.Client{Transport: proxyTransport}).Get("http://api.example.com/client/v4/user")
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	_ = response.Body.Close()
+
+	if !proxied {
+		t.Errorf("Expected the request to be sent through the proxy")
+	}
+}
+
+func TestNewRejectsInvalidConfiguration(t *testing.T) {
+	emptyBundle := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(emptyBundle, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		config   *transportConfiguration.Configuration
+		expected error
+	}{
+		{name: "proxy without scheme", config: &transportConfiguration.Configuration{Proxy: "proxy.example.com"}, expected: ErrInvalidProxy},
+		{name: "missing CA bundle", config: &transportConfiguration.Configuration{CAFile: "/nonexistent/ca.pem"}, expected: ErrInvalidCABundle},
+		{name: "CA bundle without certificates", config: &transportConfiguration.Configuration{CAFile: emptyBundle}, expected: ErrInvalidCABundle},
+		{name: "missing client certificate", config: &transportConfiguration.Configuration{ClientCertificate: "/nonexistent/client.pem"}, expected: ErrInvalidClientCertificate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := New(test.config); !errors.Is(err, test.expected) {
+				t.Errorf("Expected '%v' but got '%v'", test.expected, err)
+			}
+		})
+	}
+}
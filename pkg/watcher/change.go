@@ -0,0 +1,130 @@
+package watcher
+
+import (
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"github.com/darki73/goflaresync/pkg/log"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ActionCreate is the action of the change which creates a missing record.
+	ActionCreate = "create"
+	// ActionUpdate is the action of the change which updates an existing record.
+	ActionUpdate = "update"
+)
+
+// Change is the definition of a change of a record which is made, or would be made in the dry-run mode.
+type Change struct {
+	// Action is the action of the change.
+	Action string `json:"action"`
+	// Provider is the name of the provider instance which manages the record.
+	Provider string `json:"provider"`
+	// Zone is the name of the zone of the record.
+	Zone string `json:"zone"`
+	// RecordID is the identifier of the record, empty if the record does not exist yet.
+	RecordID string `json:"record_id,omitempty"`
+	// Name is the name of the record.
+	Name string `json:"name"`
+	// Type is the type of the record.
+	Type string `json:"type"`
+	// Attributes is the list of the changed attributes.
+	Attributes []*AttributeChange `json:"attributes"`
+}
+
+// AttributeChange is the definition of a change of a single attribute of a record.
+type AttributeChange struct {
+	// Name is the name of the attribute.
+	Name string `json:"name"`
+	// Old is the current value of the attribute, empty if the record does not exist yet.
+	Old string `json:"old"`
+	// New is the desired value of the attribute.
+	New string `json:"new"`
+}
+
+// newUpdateChange returns the change which updates the record to the desired record.
+func newUpdateChange(providerName string, record *entities.Record, desiredRecord *entities.Record, driftedAttributes []string) *Change {
+	change := &Change{
+		Action:     ActionUpdate,
+		Provider:   providerName,
+		Zone:       record.ZoneName,
+		RecordID:   record.ID,
+		Name:       record.Name,
+		Type:       record.Type,
+		Attributes: make([]*AttributeChange, 0, len(driftedAttributes)),
+	}
+
+	for _, attribute := range driftedAttributes {
+		change.Attributes = append(change.Attributes, &AttributeChange{
+			Name: attribute,
+			Old:  getAttributeValue(record, attribute),
+			New:  getAttributeValue(desiredRecord, attribute),
+		})
+	}
+
+	return change
+}
+
+// newCreateChange returns the change which creates the record in the zone.
+func newCreateChange(providerName string, zone *entities.Zone, record *entities.Record) *Change {
+	change := &Change{
+		Action:     ActionCreate,
+		Provider:   providerName,
+		Zone:       zone.Name,
+		Name:       record.Name,
+		Type:       record.Type,
+		Attributes: []*AttributeChange{},
+	}
+
+	for _, attribute := range []string{"content", "proxied", "ttl", "comment", "tags"} {
+		if value := getAttributeValue(record, attribute); value != "" {
+			change.Attributes = append(change.Attributes, &AttributeChange{
+				Name: attribute,
+				New:  value,
+			})
+		}
+	}
+
+	return change
+}
+
+// String returns the description of the change.
+func (change *Change) String() string {
+	attributes := make([]string, 0, len(change.Attributes))
+	for _, attribute := range change.Attributes {
+		attributes = append(attributes, fmt.Sprintf("%s: `%s` -> `%s`", attribute.Name, attribute.Old, attribute.New))
+	}
+
+	return fmt.Sprintf("%s record `%s` of type `%s` in zone `%s` (%s)", change.Action, change.Name, change.Type, change.Zone, strings.Join(attributes, ", "))
+}
+
+// logDryRun logs the change which would be made if the watcher was not in the dry-run mode.
+func logDryRun(change *Change) {
+	log.InfofWithFields(
+		"dry run, would %s",
+		log.FieldsMap{
+			"zone":   change.Zone,
+			"source": "watcher",
+		},
+		change.String(),
+	)
+}
+
+// getAttributeValue returns the value of the attribute of the record formatted as a string.
+func getAttributeValue(record *entities.Record, attribute string) string {
+	switch attribute {
+	case "content":
+		return record.Content
+	case "proxied":
+		return strconv.FormatBool(record.Proxied)
+	case "ttl":
+		return strconv.Itoa(record.TTL)
+	case "comment":
+		return record.Comment
+	case "tags":
+		return strings.Join(record.Tags, ",")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,68 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/api"
+	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/provider"
+)
+
+var (
+	errNoZone           = errors.New("the record does not belong to any zone")
+	errNoAddress        = errors.New("no external address was resolved")
+	errNonPublicAddress = errors.New("refusing to publish a non-public address")
+)
+
+// classifiedError is the definition of a provider error which tells why the request failed.
+type classifiedError interface {
+	error
+	// IsAuthenticationError checks if the request failed because the credentials were rejected.
+	IsAuthenticationError() bool
+	// IsTransient checks if the request may succeed if it is sent again later.
+	IsTransient() bool
+}
+
+// logAPIError logs the failed provider call along with the details reported by the Cloudflare API, if any.
+// Transient failures are logged as warnings since they are retried on the next check.
+func logAPIError(format string, fields log.FieldsMap, err error, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+
+	if errors.Is(err, context.Canceled) {
+		log.DebugfWithFields("%s: the watcher was stopped", fields, message)
+		return
+	}
+
+	var apiError *api.Error
+	if errors.As(err, &apiError) {
+		fields["status"] = apiError.StatusCode
+		if apiError.RequestID != "" {
+			fields["request_id"] = apiError.RequestID
+		}
+		if codes := apiError.GetCodes(); len(codes) > 0 {
+			fields["codes"] = codes
+		}
+	}
+
+	var providerError classifiedError
+	if !errors.As(err, &providerError) {
+		log.ErrorfWithFields("%s: %s", fields, message, err.Error())
+		return
+	}
+
+	switch {
+	case providerError.IsAuthenticationError():
+		log.ErrorfWithFields("%s, the credentials were rejected: %s", fields, message, providerError.Error())
+	case providerError.IsTransient():
+		log.WarnfWithFields("%s, will retry on the next check: %s", fields, message, providerError.Error())
+	default:
+		log.ErrorfWithFields("%s, the request was rejected: %s", fields, message, providerError.Error())
+	}
+}
+
+// isAuthenticationError checks if the error was caused by rejected credentials,
+// in which case no further API calls should be made until the next check.
+func isAuthenticationError(err error) bool {
+	return errors.Is(err, provider.ErrInvalidCredentials)
+}
@@ -0,0 +1,878 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/darki73/goflaresync/pkg/address"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	"github.com/darki73/goflaresync/pkg/configuration/records"
+	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/provider"
+	"github.com/darki73/goflaresync/pkg/provider/registry"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+)
+
+// updateDomainRecords updates the domain records, the failures are logged as they occur.
+// The update is aborted when the context is done.
+func (watcher *Watcher) updateDomainRecords(ctx context.Context) {
+	_, _ = watcher.synchronize(ctx, configuration.GetConfiguration().GetRecords(), true)
+}
+
+// Sync runs a single synchronization of the given monitored records and returns the outcome of every record.
+// All configured records are synchronized when the given records are nil.
+// The provider instances are initialized and the state is loaded unless the watcher is running.
+func (watcher *Watcher) Sync(ctx context.Context, monitoredRecords []*records.Configuration) ([]*Result, error) {
+	if watcher.providers == nil {
+		providers, err := registry.NewFromConfiguration(ctx)
+		if err != nil {
+			return nil, err
+		}
+		watcher.providers = providers
+		logIgnoredAttributes(providers, configuration.GetConfiguration().GetRecords())
+
+		if err := watcher.store.Load(); err != nil {
+			log.WarnfWithFields(
+				"failed to load state from `%s`, starting with an empty state: %s",
+				log.FieldsMap{
+					"source": "watcher",
+				},
+				watcher.store.GetPath(),
+				err.Error(),
+			)
+		}
+	}
+
+	if monitoredRecords == nil {
+		return watcher.synchronize(ctx, configuration.GetConfiguration().GetRecords(), true)
+	}
+
+	return watcher.synchronize(ctx, monitoredRecords, false)
+}
+
+// synchronize updates the monitored records and returns the outcome of every record.
+// When all records are synchronized, the known records of the state are replaced, otherwise they are merged into it.
+// In the dry-run mode all records are looked up and the state is left untouched.
+// The synchronization is aborted when the context is done.
+func (watcher *Watcher) synchronize(ctx context.Context, monitoredRecords []*records.Configuration, complete bool) ([]*Result, error) {
+	watcher.updateMutex.Lock()
+	defer watcher.updateMutex.Unlock()
+
+	if len(monitoredRecords) == 0 {
+		return []*Result{}, nil
+	}
+
+	addresses := watcher.resolveAddresses(ctx, monitoredRecords)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if len(addresses) == 0 {
+		if !watcher.dryRun {
+			watcher.saveFailure(errNoAddress)
+		}
+		return nil, errNoAddress
+	}
+
+	if watcher.dryRun {
+		results, _ := watcher.reconcileRecords(ctx, monitoredRecords, addresses)
+		return results, nil
+	}
+
+	if !watcher.isReconciliationDue() {
+		if watcher.isUpToDate(monitoredRecords, addresses) {
+			log.DebugWithFields(
+				"address has not changed since the last synchronization, skipping",
+				log.FieldsMap{
+					"source": "watcher",
+				},
+			)
+			watcher.saveState(addresses, false)
+			return watcher.getKnownResults(monitoredRecords, addresses), nil
+		}
+
+		if results, successful := watcher.updateKnownRecords(ctx, monitoredRecords, addresses); successful {
+			watcher.saveState(addresses, false)
+			return results, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		log.DebugWithFields(
+			"known records are not sufficient, looking up all records",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+		)
+	}
+
+	results, successful := watcher.reconcileRecords(ctx, monitoredRecords, addresses)
+	if !successful {
+		if ctx.Err() == nil {
+			watcher.saveFailure(getFailure(results))
+		}
+		return results, nil
+	}
+
+	if complete {
+		watcher.store.SetRecords(getKnownRecords(results))
+	} else {
+		for _, result := range results {
+			for _, record := range result.GetRecords() {
+				watcher.store.PutRecord(result.GetRecord().GetProvider(), record)
+			}
+		}
+	}
+	watcher.saveState(addresses, complete)
+
+	return results, nil
+}
+
+// UpdateRecords looks up the given records and updates the ones which are out of date with the given addresses, keyed by the network.
+// It returns the outcome of every processed record, the state of the watcher is not taken into account.
+func (watcher *Watcher) UpdateRecords(ctx context.Context, monitoredRecords []*records.Configuration, addresses map[string]netip.Addr) []*Result {
+	watcher.updateMutex.Lock()
+	defer watcher.updateMutex.Unlock()
+
+	results, _ := watcher.reconcileRecords(ctx, monitoredRecords, addresses)
+	return results
+}
+
+// reconcileRecords looks up every monitored record and updates the ones which are out of date.
+// It returns the outcome of every record along with a flag that indicates if all records were successfully processed.
+// The remaining records are marked as failed without being processed once the credentials are rejected or the context is done.
+func (watcher *Watcher) reconcileRecords(ctx context.Context, monitoredRecords []*records.Configuration, addresses map[string]netip.Addr) ([]*Result, bool) {
+	zones := make(map[string]*entities.Zone)
+	results := make([]*Result, 0, len(monitoredRecords))
+
+	for index, monitoredRecord := range monitoredRecords {
+		if ctx.Err() != nil {
+			return append(results, getAbortedResults(monitoredRecords[index:], ctx.Err())...), false
+		}
+
+		result := watcher.reconcileRecord(ctx, monitoredRecord, addresses, zones)
+		results = append(results, result)
+
+		if isAuthenticationError(result.GetError()) {
+			return append(results, getAbortedResults(monitoredRecords[index+1:], result.GetError())...), false
+		}
+	}
+
+	for _, result := range results {
+		if !result.IsSuccessful() {
+			return results, false
+		}
+	}
+
+	return results, true
+}
+
+// reconcileRecord looks up the monitored record and updates it if it is out of date.
+// The zones which were already looked up are taken from the given cache.
+func (watcher *Watcher) reconcileRecord(ctx context.Context, monitoredRecord *records.Configuration, addresses map[string]netip.Addr, zones map[string]*entities.Zone) *Result {
+	externalAddress, err := watcher.getRecordAddress(monitoredRecord, addresses)
+	if err != nil {
+		result := newResult(monitoredRecord, "", StatusSkipped)
+		result.Error = err
+		return result
+	}
+
+	result := newResult(monitoredRecord, externalAddress, StatusUnchanged)
+
+	recordProvider, err := watcher.getRecordProvider(monitoredRecord)
+	if err != nil {
+		result.fail(err)
+		return result
+	}
+
+	zone, err := watcher.findZone(ctx, recordProvider, monitoredRecord.GetName(), zones)
+	if err != nil {
+		logAPIError(
+			"failed to find zone for record `%s`",
+			log.FieldsMap{
+				"source": "api",
+			},
+			err,
+			monitoredRecord.GetName(),
+		)
+		result.fail(err)
+		return result
+	}
+
+	if zone == nil {
+		log.WarnfWithFields(
+			"record `%s` of type `%s` does not belong to any zone",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+			monitoredRecord.GetName(),
+			monitoredRecord.GetType(),
+		)
+		if monitoredRecord.GetCreateIfMissing() {
+			result.fail(errNoZone)
+			return result
+		}
+		result.Status = StatusNotFound
+		return result
+	}
+
+	zoneRecords, err := recordProvider.FindRecordsWithContext(ctx, zone, monitoredRecord.GetName(), monitoredRecord.GetType())
+	if err != nil {
+		logAPIError(
+			"failed to find record `%s` in zone `%s`",
+			log.FieldsMap{
+				"zone":   zone.ID,
+				"source": "api",
+			},
+			err,
+			monitoredRecord.GetName(),
+			zone.Name,
+		)
+		result.fail(err)
+		return result
+	}
+
+	if len(zoneRecords) == 0 {
+		if !monitoredRecord.GetCreateIfMissing() {
+			log.WarnfWithFields(
+				"record `%s` of type `%s` was not found in zone `%s`",
+				log.FieldsMap{
+					"zone":   zone.ID,
+					"source": "watcher",
+				},
+				monitoredRecord.GetName(),
+				monitoredRecord.GetType(),
+				zone.Name,
+			)
+			result.Status = StatusNotFound
+			return result
+		}
+
+		change := newCreateChange(recordProvider.GetName(), zone, newRecord(getSupportedConfiguration(recordProvider, monitoredRecord), externalAddress))
+		if watcher.dryRun {
+			logDryRun(change)
+			result.Status = StatusCreated
+			result.Changes = append(result.Changes, change)
+			return result
+		}
+
+		createdRecord, err := watcher.createRecord(ctx, recordProvider, zone, monitoredRecord, externalAddress)
+		if err != nil {
+			result.fail(err)
+			return result
+		}
+
+		result.Status = StatusCreated
+		result.Records = append(result.Records, createdRecord)
+		result.Changes = append(result.Changes, change)
+		return result
+	}
+
+	for _, zoneRecord := range zoneRecords {
+		zoneRecord.ZoneID = zone.ID
+		zoneRecord.ZoneName = zone.Name
+
+		change, err := watcher.updateRecord(ctx, recordProvider, zoneRecord, monitoredRecord, externalAddress)
+		if err != nil {
+			result.fail(err)
+			if isAuthenticationError(err) {
+				return result
+			}
+		} else if change != nil {
+			if result.IsSuccessful() {
+				result.Status = StatusUpdated
+			}
+			result.Changes = append(result.Changes, change)
+		}
+
+		result.Records = append(result.Records, zoneRecord)
+	}
+
+	return result
+}
+
+// getAbortedResults returns the results of the monitored records which were not processed because of the given error.
+func getAbortedResults(monitoredRecords []*records.Configuration, err error) []*Result {
+	results := make([]*Result, 0, len(monitoredRecords))
+	for _, monitoredRecord := range monitoredRecords {
+		result := newResult(monitoredRecord, "", StatusFailed)
+		result.Error = err
+		results = append(results, result)
+	}
+	return results
+}
+
+// getFailure returns the error of the first failed record.
+func getFailure(results []*Result) error {
+	for _, result := range results {
+		if !result.IsSuccessful() {
+			return fmt.Errorf("record `%s` of type `%s`: %w", result.GetRecord().GetName(), result.GetRecord().GetType(), result.GetError())
+		}
+	}
+	return nil
+}
+
+// getKnownResults returns the outcome of the monitored records which are known to be up to date.
+func (watcher *Watcher) getKnownResults(monitoredRecords []*records.Configuration, addresses map[string]netip.Addr) []*Result {
+	results := make([]*Result, 0, len(monitoredRecords))
+	for _, monitoredRecord := range monitoredRecords {
+		externalAddress, err := watcher.getRecordAddress(monitoredRecord, addresses)
+		if err != nil {
+			result := newResult(monitoredRecord, "", StatusSkipped)
+			result.Error = err
+			results = append(results, result)
+			continue
+		}
+
+		result := newResult(monitoredRecord, externalAddress, StatusUnchanged)
+		result.Records = watcher.store.GetRecords(monitoredRecord.GetProvider(), monitoredRecord.GetType(), monitoredRecord.GetName())
+		results = append(results, result)
+	}
+	return results
+}
+
+// getKnownRecords returns the records of the providers which match the monitored records, keyed by the provider name.
+func getKnownRecords(results []*Result) map[string][]*entities.Record {
+	knownRecords := make(map[string][]*entities.Record)
+	for _, result := range results {
+		providerName := result.GetRecord().GetProvider()
+		knownRecords[providerName] = append(knownRecords[providerName], result.GetRecords()...)
+	}
+	return knownRecords
+}
+
+// getRecordProvider returns the provider instance the monitored record is managed by.
+func (watcher *Watcher) getRecordProvider(monitoredRecord *records.Configuration) (provider.Provider, error) {
+	recordProvider, err := watcher.providers.Get(monitoredRecord.GetProvider())
+	if err != nil {
+		log.ErrorfWithFields(
+			"record `%s` of type `%s` is not monitored: %s",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+			monitoredRecord.GetName(),
+			monitoredRecord.GetType(),
+			err.Error(),
+		)
+		return nil, err
+	}
+
+	return recordProvider, nil
+}
+
+// findZone returns the zone of the provider with the longest name the record name belongs to, or nil if there is none.
+// The zones which were already looked up are taken from the given cache.
+func (watcher *Watcher) findZone(ctx context.Context, recordProvider provider.Provider, name string, zones map[string]*entities.Zone) (*entities.Zone, error) {
+	for _, candidate := range getZoneNameCandidates(name) {
+		key := recordProvider.GetName() + "/" + candidate
+		if zone, ok := zones[key]; ok {
+			if zone != nil {
+				return zone, nil
+			}
+			continue
+		}
+
+		zone, err := recordProvider.FindZoneWithContext(ctx, candidate)
+		if err != nil && !errors.Is(err, provider.ErrZoneNotFound) {
+			return nil, err
+		}
+
+		zones[key] = zone
+
+		if zone != nil {
+			return zone, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// createRecord creates the monitored record in the given zone.
+func (watcher *Watcher) createRecord(ctx context.Context, recordProvider provider.Provider, zone *entities.Zone, monitoredRecord *records.Configuration, externalAddress string) (*entities.Record, error) {
+	createdRecord, err := recordProvider.CreateRecordWithContext(ctx, zone, newRecord(getSupportedConfiguration(recordProvider, monitoredRecord), externalAddress))
+	if err != nil {
+		logAPIError(
+			"failed to create record `%s`",
+			log.FieldsMap{
+				"zone":   zone.ID,
+				"source": "api",
+			},
+			err,
+			monitoredRecord.GetName(),
+		)
+		return nil, err
+	}
+
+	log.InfofWithFields(
+		"created record `%s` with `%s`",
+		log.FieldsMap{
+			"zone":   zone.ID,
+			"record": createdRecord.ID,
+			"source": "watcher",
+		},
+		createdRecord.Name,
+		createdRecord.Content,
+	)
+
+	return createdRecord, nil
+}
+
+// newRecord returns the record which is created for the monitored record with the given address.
+// Proxied records are created with the automatic TTL.
+func newRecord(monitoredRecord *records.Configuration, externalAddress string) *entities.Record {
+	ttl := monitoredRecord.GetTTL()
+	if monitoredRecord.GetProxied() {
+		ttl = records.DefaultTTL
+	}
+
+	return &entities.Record{
+		Content: externalAddress,
+		Name:    monitoredRecord.GetName(),
+		Proxied: monitoredRecord.GetProxied(),
+		Type:    monitoredRecord.GetType(),
+		Comment: monitoredRecord.GetComment(),
+		TTL:     ttl,
+		Tags:    monitoredRecord.GetTags(),
+	}
+}
+
+// getSupportedConfiguration returns a copy of the monitored record without the attributes the provider does not store,
+// so they are neither applied nor compared with the records of the provider.
+func getSupportedConfiguration(recordProvider provider.Provider, monitoredRecord *records.Configuration) *records.Configuration {
+	supportedRecord := *monitoredRecord
+
+	if !recordProvider.SupportsAttribute(provider.AttributeProxied) {
+		supportedRecord.Proxied = nil
+	}
+
+	if !recordProvider.SupportsAttribute(provider.AttributeTTL) {
+		supportedRecord.TTL = 0
+	}
+
+	if !recordProvider.SupportsAttribute(provider.AttributeComment) {
+		supportedRecord.Comment = nil
+	}
+
+	if !recordProvider.SupportsAttribute(provider.AttributeTags) {
+		supportedRecord.Tags = nil
+	}
+
+	return &supportedRecord
+}
+
+// getIgnoredAttributes returns the names of the attributes declared for the monitored record which the provider does not store.
+func getIgnoredAttributes(recordProvider provider.Provider, monitoredRecord *records.Configuration) []string {
+	var ignoredAttributes []string
+
+	if monitoredRecord.HasProxied() && !recordProvider.SupportsAttribute(provider.AttributeProxied) {
+		ignoredAttributes = append(ignoredAttributes, provider.AttributeProxied)
+	}
+
+	if monitoredRecord.HasTTL() && !recordProvider.SupportsAttribute(provider.AttributeTTL) {
+		ignoredAttributes = append(ignoredAttributes, provider.AttributeTTL)
+	}
+
+	if monitoredRecord.HasComment() && !recordProvider.SupportsAttribute(provider.AttributeComment) {
+		ignoredAttributes = append(ignoredAttributes, provider.AttributeComment)
+	}
+
+	if monitoredRecord.HasTags() && !recordProvider.SupportsAttribute(provider.AttributeTags) {
+		ignoredAttributes = append(ignoredAttributes, provider.AttributeTags)
+	}
+
+	return ignoredAttributes
+}
+
+// logIgnoredAttributes warns about the configured attributes of the monitored records which are not applied.
+func logIgnoredAttributes(providers *registry.Registry, monitoredRecords []*records.Configuration) {
+	for _, monitoredRecord := range monitoredRecords {
+		recordProvider, err := providers.Get(monitoredRecord.GetProvider())
+		if err != nil {
+			continue
+		}
+
+		if ignoredAttributes := getIgnoredAttributes(recordProvider, monitoredRecord); len(ignoredAttributes) > 0 {
+			log.WarnfWithFields(
+				"record `%s` of type `%s` declares `%s` which provider `%s` does not support, they are ignored",
+				log.FieldsMap{
+					"source": "watcher",
+				},
+				monitoredRecord.GetName(),
+				monitoredRecord.GetType(),
+				strings.Join(ignoredAttributes, "`, `"),
+				recordProvider.GetName(),
+			)
+			monitoredRecord = getSupportedConfiguration(recordProvider, monitoredRecord)
+		}
+
+		if monitoredRecord.GetProxied() && monitoredRecord.HasTTL() {
+			log.WarnfWithFields(
+				"record `%s` of type `%s` is proxied, its TTL is automatic and the configured `ttl` is ignored",
+				log.FieldsMap{
+					"source": "watcher",
+				},
+				monitoredRecord.GetName(),
+				monitoredRecord.GetType(),
+			)
+		}
+	}
+}
+
+// getZoneNameCandidates returns the names of the zones the record name could belong to, longest first.
+func getZoneNameCandidates(name string) []string {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(name), "."), ".")
+
+	var candidates []string
+	for index := 0; index < len(labels)-1; index++ {
+		candidates = append(candidates, strings.Join(labels[index:], "."))
+	}
+
+	return candidates
+}
+
+// updateKnownRecords updates the records remembered in the state without looking up all records.
+// It returns the outcome of every record along with a flag that indicates if the records have to be reconciled instead.
+func (watcher *Watcher) updateKnownRecords(ctx context.Context, monitoredRecords []*records.Configuration, addresses map[string]netip.Addr) ([]*Result, bool) {
+	results := make([]*Result, 0, len(monitoredRecords))
+
+	for _, monitoredRecord := range monitoredRecords {
+		externalAddress, err := watcher.getRecordAddress(monitoredRecord, addresses)
+		if err != nil {
+			result := newResult(monitoredRecord, "", StatusSkipped)
+			result.Error = err
+			results = append(results, result)
+			continue
+		}
+
+		recordProvider, err := watcher.getRecordProvider(monitoredRecord)
+		if err != nil {
+			return nil, false
+		}
+
+		knownRecords := watcher.store.GetRecords(recordProvider.GetName(), monitoredRecord.GetType(), monitoredRecord.GetName())
+		if len(knownRecords) == 0 {
+			return nil, false
+		}
+
+		result := newResult(monitoredRecord, externalAddress, StatusUnchanged)
+		for _, knownRecord := range knownRecords {
+			change, err := watcher.updateRecord(ctx, recordProvider, knownRecord, monitoredRecord, externalAddress)
+			if err != nil {
+				return nil, false
+			}
+			if change != nil {
+				result.Status = StatusUpdated
+				result.Changes = append(result.Changes, change)
+			}
+			watcher.store.PutRecord(recordProvider.GetName(), knownRecord)
+			result.Records = append(result.Records, knownRecord)
+		}
+		results = append(results, result)
+	}
+
+	return results, true
+}
+
+// updateRecord updates the record if its content or any of the managed attributes differ from the desired ones.
+// It returns the change made to the record, nil if the record was up to date, or an error if the record could not be updated.
+// The record is left untouched in the dry-run mode, the returned change is only logged.
+func (watcher *Watcher) updateRecord(ctx context.Context, recordProvider provider.Provider, record *entities.Record, monitoredRecord *records.Configuration, externalAddress string) (*Change, error) {
+	desiredRecord, driftedAttributes := getDesiredRecord(record, getSupportedConfiguration(recordProvider, monitoredRecord), externalAddress)
+
+	if len(driftedAttributes) == 0 {
+		log.InfofWithFields(
+			"record `%s` is already up to date",
+			log.FieldsMap{
+				"zone":   record.ZoneID,
+				"record": record.ID,
+				"source": "watcher",
+			},
+			record.Name,
+		)
+		return nil, nil
+	}
+
+	change := newUpdateChange(recordProvider.GetName(), record, desiredRecord, driftedAttributes)
+	if watcher.dryRun {
+		logDryRun(change)
+		return change, nil
+	}
+
+	zone := &entities.Zone{
+		ID:   record.ZoneID,
+		Name: record.ZoneName,
+	}
+
+	updatedRecord, err := recordProvider.UpdateRecordWithContext(ctx, zone, desiredRecord)
+	if err != nil {
+		logAPIError(
+			"failed to update record `%s`",
+			log.FieldsMap{
+				"zone":   record.ZoneID,
+				"record": record.ID,
+				"source": "api",
+			},
+			err,
+			record.Name,
+		)
+		return nil, err
+	}
+
+	*record = *updatedRecord
+
+	log.InfofWithFields(
+		"updated record `%s` to `%s`",
+		log.FieldsMap{
+			"zone":    record.ZoneID,
+			"record":  record.ID,
+			"changes": strings.Join(driftedAttributes, ","),
+			"source":  "watcher",
+		},
+		record.Name,
+		record.Content,
+	)
+
+	return change, nil
+}
+
+// getDesiredRecord returns a copy of the record with the desired content and managed attributes
+// along with the list of attributes which differ from the desired ones.
+// Attributes which are not configured for the monitored record are preserved.
+func getDesiredRecord(record *entities.Record, monitoredRecord *records.Configuration, externalAddress string) (*entities.Record, []string) {
+	desiredRecord := *record
+	var driftedAttributes []string
+
+	if record.Content != externalAddress {
+		desiredRecord.Content = externalAddress
+		driftedAttributes = append(driftedAttributes, "content")
+	}
+
+	if monitoredRecord.HasProxied() && record.Proxied != monitoredRecord.GetProxied() {
+		desiredRecord.Proxied = monitoredRecord.GetProxied()
+		driftedAttributes = append(driftedAttributes, "proxied")
+	}
+
+	// Proxied records always report the automatic TTL, so the configured TTL is not applied to them.
+	if monitoredRecord.HasTTL() && !desiredRecord.Proxied && record.TTL != monitoredRecord.GetTTL() {
+		desiredRecord.TTL = monitoredRecord.GetTTL()
+		driftedAttributes = append(driftedAttributes, "ttl")
+	}
+
+	if monitoredRecord.HasComment() && record.Comment != monitoredRecord.GetComment() {
+		desiredRecord.Comment = monitoredRecord.GetComment()
+		driftedAttributes = append(driftedAttributes, "comment")
+	}
+
+	if monitoredRecord.HasTags() && !isSameTags(record.Tags, monitoredRecord.GetTags()) {
+		desiredRecord.Tags = append([]string{}, monitoredRecord.GetTags()...)
+		driftedAttributes = append(driftedAttributes, "tags")
+	}
+
+	return &desiredRecord, driftedAttributes
+}
+
+// isSameTags checks if both lists contain the same tags regardless of their order.
+func isSameTags(current []string, desired []string) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+
+	sortedCurrent := append([]string{}, current...)
+	sortedDesired := append([]string{}, desired...)
+	sort.Strings(sortedCurrent)
+	sort.Strings(sortedDesired)
+
+	for index := range sortedCurrent {
+		if sortedCurrent[index] != sortedDesired[index] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isUpToDate checks if every monitored record is known and already matches the current address and managed attributes.
+func (watcher *Watcher) isUpToDate(monitoredRecords []*records.Configuration, addresses map[string]netip.Addr) bool {
+	for network, externalAddress := range addresses {
+		if watcher.store.GetAddress(network) != externalAddress.String() {
+			return false
+		}
+	}
+
+	for _, monitoredRecord := range monitoredRecords {
+		externalAddress, err := watcher.getRecordAddress(monitoredRecord, addresses)
+		if err != nil {
+			continue
+		}
+
+		recordProvider, err := watcher.providers.Get(monitoredRecord.GetProvider())
+		if err != nil {
+			return false
+		}
+
+		knownRecords := watcher.store.GetRecords(monitoredRecord.GetProvider(), monitoredRecord.GetType(), monitoredRecord.GetName())
+		if len(knownRecords) == 0 {
+			return false
+		}
+
+		for _, knownRecord := range knownRecords {
+			if _, driftedAttributes := getDesiredRecord(knownRecord, getSupportedConfiguration(recordProvider, monitoredRecord), externalAddress); len(driftedAttributes) > 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isReconciliationDue checks if all records have to be listed again.
+func (watcher *Watcher) isReconciliationDue() bool {
+	lastReconciliation := watcher.store.GetLastReconciliation()
+	if lastReconciliation.IsZero() {
+		return true
+	}
+
+	return time.Since(lastReconciliation) >= watcher.reconciliationInterval
+}
+
+// saveState records the successful synchronization and persists the state.
+func (watcher *Watcher) saveState(addresses map[string]netip.Addr, reconciled bool) {
+	now := time.Now()
+
+	for network, externalAddress := range addresses {
+		watcher.store.SetAddress(network, externalAddress.String())
+	}
+
+	watcher.store.SetLastSync(now)
+	watcher.store.SetLastAttempt(now)
+	watcher.store.SetLastError("")
+	if reconciled {
+		watcher.store.SetLastReconciliation(now)
+	}
+
+	if err := watcher.store.Save(); err != nil {
+		log.WarnfWithFields(
+			"failed to save state to `%s`: %s",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+			watcher.store.GetPath(),
+			err.Error(),
+		)
+	}
+}
+
+// saveFailure persists the time and the error of the failed synchronization, the published addresses are kept.
+func (watcher *Watcher) saveFailure(err error) {
+	watcher.store.SetLastAttempt(time.Now())
+	if err != nil {
+		watcher.store.SetLastError(err.Error())
+	}
+
+	if err := watcher.store.Save(); err != nil {
+		log.WarnfWithFields(
+			"failed to save state to `%s`: %s",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+			watcher.store.GetPath(),
+			err.Error(),
+		)
+	}
+}
+
+// resolveAddresses resolves the external address for every address family used by the monitored records.
+func (watcher *Watcher) resolveAddresses(ctx context.Context, monitoredRecords []*records.Configuration) map[string]netip.Addr {
+	addresses := make(map[string]netip.Addr)
+	failed := make(map[string]bool)
+
+	for _, monitoredRecord := range monitoredRecords {
+		network, err := address.GetNetworkForRecordType(monitoredRecord.GetType())
+		if err != nil {
+			log.WarnfWithFields(
+				"record `%s` is not monitored: %s",
+				log.FieldsMap{
+					"source": "watcher",
+				},
+				monitoredRecord.GetName(),
+				err.Error(),
+			)
+			continue
+		}
+
+		if _, ok := addresses[network]; ok || failed[network] {
+			continue
+		}
+
+		externalAddress, err := watcher.resolveAddress(ctx, network)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			log.ErrorfWithFields(
+				"failed to get external address over `%s`: %s",
+				log.FieldsMap{
+					"source": "address",
+				},
+				network,
+				err.Error(),
+			)
+			failed[network] = true
+			continue
+		}
+
+		addresses[network] = externalAddress
+	}
+
+	return addresses
+}
+
+// resolveAddress resolves the external address for the given network.
+// The resolver is built on first use and reused until the watcher is configured again.
+func (watcher *Watcher) resolveAddress(ctx context.Context, network string) (netip.Addr, error) {
+	resolver, ok := watcher.resolvers[network]
+	if !ok {
+		var err error
+		if resolver, err = watcher.newResolver(network); err != nil {
+			return netip.Addr{}, err
+		}
+		watcher.resolvers[network] = resolver
+	}
+
+	return resolver.ResolveWithContext(ctx)
+}
+
+// getRecordAddress returns the address the record should point to.
+// It returns an error if no address is available for the record or the address must not be published.
+func (watcher *Watcher) getRecordAddress(monitoredRecord *records.Configuration, addresses map[string]netip.Addr) (string, error) {
+	network, err := address.GetNetworkForRecordType(monitoredRecord.GetType())
+	if err != nil {
+		return "", err
+	}
+
+	externalAddress, ok := addresses[network]
+	if !ok {
+		return "", fmt.Errorf("%w over `%s`", errNoAddress, network)
+	}
+
+	if address.IsBogon(externalAddress) && !monitoredRecord.GetAllowPrivateAddress() {
+		log.WarnfWithFields(
+			"refusing to publish non-public address `%s` for record `%s`",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+			externalAddress.String(),
+			monitoredRecord.GetName(),
+		)
+		return "", fmt.Errorf("%w: %s", errNonPublicAddress, externalAddress.String())
+	}
+
+	return externalAddress.String(), nil
+}
@@ -0,0 +1,139 @@
+package watcher
+
+import (
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"github.com/darki73/goflaresync/pkg/configuration/records"
+	"testing"
+)
+
+func TestGetZoneNameCandidates(t *testing.T) {
+	tests := []struct {
+		name   string
+		output []string
+	}{
+		{"example.com", []string{"example.com"}},
+		{"www.example.com", []string{"www.example.com", "example.com"}},
+		{"API.Dev.Example.com.", []string{"api.dev.example.com", "dev.example.com", "example.com"}},
+		{"localhost", nil},
+	}
+
+	for _, test := range tests {
+		result := getZoneNameCandidates(test.name)
+
+		if len(result) != len(test.output) {
+			t.Errorf("for name %s, expected %v but got %v", test.name, test.output, result)
+			continue
+		}
+
+		for index := range result {
+			if result[index] != test.output[index] {
+				t.Errorf("for name %s, expected %v but got %v", test.name, test.output, result)
+				break
+			}
+		}
+	}
+}
+
+func TestGetDesiredRecordPreservesUnmanagedAttributes(t *testing.T) {
+	record := &entities.Record{
+		ID:      "record-1",
+		Name:    "example.com",
+		Type:    "A",
+		Content: "198.51.100.7",
+		Proxied: true,
+		TTL:     300,
+		Comment: "manual",
+		Tags:    []string{"owner:ops"},
+	}
+
+	desiredRecord, driftedAttributes := getDesiredRecord(record, &records.Configuration{Name: "example.com", Type: "A"}, "198.51.100.8")
+
+	if len(driftedAttributes) != 1 || driftedAttributes[0] != "content" {
+		t.Errorf("Expected only 'content' to drift but got %v", driftedAttributes)
+	}
+
+	if desiredRecord.Content != "198.51.100.8" {
+		t.Errorf("Expected '198.51.100.8' but got '%s'", desiredRecord.Content)
+	}
+
+	if !desiredRecord.Proxied || desiredRecord.TTL != 300 || desiredRecord.Comment != "manual" || len(desiredRecord.Tags) != 1 {
+		t.Errorf("Expected unmanaged attributes to be preserved but got %+v", desiredRecord)
+	}
+
+	if record.Content != "198.51.100.7" {
+		t.Errorf("Expected original record to be left untouched but got '%s'", record.Content)
+	}
+}
+
+func TestGetDesiredRecordDetectsAttributeDrift(t *testing.T) {
+	proxied := false
+	comment := "Managed by GoFlareSync"
+
+	record := &entities.Record{
+		Name:    "example.com",
+		Type:    "A",
+		Content: "198.51.100.7",
+		Proxied: true,
+		TTL:     1,
+		Comment: "",
+		Tags:    []string{"b:2", "a:1"},
+	}
+
+	monitoredRecord := &records.Configuration{
+		Name:    "example.com",
+		Type:    "A",
+		Proxied: &proxied,
+		TTL:     300,
+		Comment: &comment,
+		Tags:    []string{"a:1", "b:2"},
+	}
+
+	desiredRecord, driftedAttributes := getDesiredRecord(record, monitoredRecord, "198.51.100.7")
+
+	expected := []string{"proxied", "ttl", "comment"}
+	if len(driftedAttributes) != len(expected) {
+		t.Fatalf("Expected %v but got %v", expected, driftedAttributes)
+	}
+
+	for index := range expected {
+		if driftedAttributes[index] != expected[index] {
+			t.Errorf("Expected %v but got %v", expected, driftedAttributes)
+		}
+	}
+
+	if desiredRecord.Proxied || desiredRecord.TTL != 300 || desiredRecord.Comment != comment {
+		t.Errorf("Expected managed attributes to be applied but got %+v", desiredRecord)
+	}
+
+	monitoredRecord.Tags = []string{}
+	if _, driftedAttributes := getDesiredRecord(record, monitoredRecord, "198.51.100.7"); driftedAttributes[len(driftedAttributes)-1] != "tags" {
+		t.Errorf("Expected 'tags' to drift but got %v", driftedAttributes)
+	}
+}
+
+func TestGetDesiredRecordIgnoresTTLOfProxiedRecord(t *testing.T) {
+	proxied := true
+
+	record := &entities.Record{
+		Name:    "example.com",
+		Type:    "A",
+		Content: "198.51.100.7",
+		Proxied: true,
+		TTL:     1,
+	}
+
+	desiredRecord, driftedAttributes := getDesiredRecord(record, &records.Configuration{Name: "example.com", Type: "A", Proxied: &proxied, TTL: 300}, "198.51.100.7")
+
+	if len(driftedAttributes) != 0 {
+		t.Errorf("Expected no drift but got %v", driftedAttributes)
+	}
+
+	if desiredRecord.TTL != 1 {
+		t.Errorf("Expected the automatic TTL to be kept but got %d", desiredRecord.TTL)
+	}
+
+	record.Proxied = false
+	if _, driftedAttributes := getDesiredRecord(record, &records.Configuration{Name: "example.com", Type: "A", TTL: 300}, "198.51.100.7"); len(driftedAttributes) != 1 || driftedAttributes[0] != "ttl" {
+		t.Errorf("Expected 'ttl' to drift but got %v", driftedAttributes)
+	}
+}
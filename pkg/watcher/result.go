@@ -0,0 +1,97 @@
+package watcher
+
+import (
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"github.com/darki73/goflaresync/pkg/configuration/records"
+)
+
+// Status is the definition of the outcome of the synchronization of a monitored record.
+type Status string
+
+const (
+	// StatusUnchanged indicates that the record was already up to date.
+	StatusUnchanged Status = "unchanged"
+	// StatusUpdated indicates that the record was updated.
+	StatusUpdated Status = "updated"
+	// StatusCreated indicates that the missing record was created.
+	StatusCreated Status = "created"
+	// StatusNotFound indicates that the record or its zone does not exist and the record was not created.
+	StatusNotFound Status = "not_found"
+	// StatusSkipped indicates that no address could be published for the record.
+	StatusSkipped Status = "skipped"
+	// StatusFailed indicates that the record could not be looked up or changed.
+	StatusFailed Status = "failed"
+)
+
+// Result is the definition of the outcome of the synchronization of a monitored record.
+type Result struct {
+	// Record is the monitored record.
+	Record *records.Configuration
+	// Address is the address the record was synchronized with, empty if there was none.
+	Address string
+	// Status is the outcome of the synchronization.
+	Status Status
+	// Error is the error which caused the synchronization to fail, nil if it did not fail.
+	Error error
+	// Records is the list of records of the provider which match the monitored record.
+	Records []*entities.Record
+	// Changes is the list of changes made to the records, or which would be made in the dry-run mode.
+	Changes []*Change
+}
+
+// newResult returns a new result of the monitored record with the given status.
+func newResult(monitoredRecord *records.Configuration, externalAddress string, status Status) *Result {
+	return &Result{
+		Record:  monitoredRecord,
+		Address: externalAddress,
+		Status:  status,
+		Records: []*entities.Record{},
+		Changes: []*Change{},
+	}
+}
+
+// GetRecord returns the monitored record.
+func (result *Result) GetRecord() *records.Configuration {
+	return result.Record
+}
+
+// GetAddress returns the address the record was synchronized with.
+func (result *Result) GetAddress() string {
+	return result.Address
+}
+
+// GetStatus returns the outcome of the synchronization.
+func (result *Result) GetStatus() Status {
+	return result.Status
+}
+
+// GetError returns the error which caused the synchronization to fail.
+func (result *Result) GetError() error {
+	return result.Error
+}
+
+// GetRecords returns the list of records of the provider which match the monitored record.
+func (result *Result) GetRecords() []*entities.Record {
+	return result.Records
+}
+
+// GetChanges returns the list of changes made to the records, or which would be made in the dry-run mode.
+func (result *Result) GetChanges() []*Change {
+	return result.Changes
+}
+
+// IsSuccessful checks if the record is in the desired state or there was nothing to do for it.
+func (result *Result) IsSuccessful() bool {
+	return result.Status != StatusFailed
+}
+
+// IsChanged checks if the record was updated or created.
+func (result *Result) IsChanged() bool {
+	return result.Status == StatusUpdated || result.Status == StatusCreated
+}
+
+// fail marks the result as failed with the given error.
+func (result *Result) fail(err error) {
+	result.Status = StatusFailed
+	result.Error = err
+}
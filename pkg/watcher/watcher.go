@@ -1,10 +1,12 @@
 package watcher
 
 import (
-	"github.com/darki73/goflaresync/pkg/api"
+	"context"
+	"github.com/darki73/goflaresync/pkg/address"
 	"github.com/darki73/goflaresync/pkg/configuration"
-	"github.com/darki73/goflaresync/pkg/helpers"
 	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/provider/registry"
+	"github.com/darki73/goflaresync/pkg/state"
 	"sync"
 	"time"
 )
@@ -13,8 +15,8 @@ import (
 type Watcher struct {
 	// interval is the interval at which the watcher will run.
 	interval time.Duration
-	// client is the Cloudflare API client.
-	client *api.API
+	// providers is the registry of the DNS provider instances the monitored records refer to.
+	providers *registry.Registry
 	// ticker is the ticker of the watcher.
 	ticker *time.Ticker
 	// stopChannel is the channel used to stop the watcher.
@@ -23,14 +25,89 @@ type Watcher struct {
 	waitGroup sync.WaitGroup
 	// running is a flag that indicates if the watcher is running.
 	running bool
+	// monitor is the monitor which reports local address changes.
+	monitor addressMonitor
+	// newMonitor returns the monitor of local address changes.
+	newMonitor func() (addressMonitor, error)
+	// debounce is the time to wait for further local address changes before the records are updated.
+	debounce time.Duration
+	// updateMutex ensures that only one update of the domain records runs at a time.
+	updateMutex sync.Mutex
+	// store is the store of the state persisted between the synchronizations.
+	store *state.Store
+	// reconciliationInterval is the interval at which all records are looked up even if the address has not changed.
+	reconciliationInterval time.Duration
+	// newResolver returns the resolver of the external address for the given network.
+	newResolver func(network string) (*address.Resolver, error)
+	// resolvers is the resolver of the external address for every network, it is built once per start and reused by every update.
+	resolvers map[string]*address.Resolver
+	// syncChannel is the channel used to request an immediate update of the domain records.
+	syncChannel chan struct{}
+	// dryRun is a flag that indicates if the changes are only reported without being made.
+	dryRun bool
+	// cancel cancels the context of the running watcher and aborts the in-flight requests.
+	cancel context.CancelFunc
+}
+
+// addressMonitor is the definition of the monitor which reports local address changes.
+type addressMonitor interface {
+	// GetEvents returns the channel which receives a value every time a local address changes.
+	GetEvents() <-chan struct{}
+	// Close stops the monitor.
+	Close() error
 }
 
 // New returns a new watcher.
 func New() *Watcher {
-	return &Watcher{
-		interval: configuration.GetConfiguration().GetWatcher().GetInterval(),
-		client:   nil,
+	watcher := &Watcher{
+		providers:   nil,
+		newResolver: address.NewResolverFromConfiguration,
+		newMonitor:  newAddressMonitor,
+		syncChannel: make(chan struct{}, 1),
+	}
+	watcher.configure()
+
+	return watcher
+}
+
+// NewWithProviders returns a new watcher which manages the records with the given provider instances.
+// The state of the returned watcher is not persisted, it is meant to apply the addresses pushed by clients with UpdateRecords.
+func NewWithProviders(providers *registry.Registry) *Watcher {
+	watcher := New()
+	watcher.providers = providers
+	watcher.store = state.NewStore("")
+	return watcher
+}
+
+// configure applies the current watcher configuration, it is called again on every start
+// so the configuration reloaded since the watcher was created is picked up by a restart.
+func (watcher *Watcher) configure() {
+	config := configuration.GetConfiguration().GetWatcher()
+
+	watcher.interval = config.GetInterval()
+	watcher.debounce = config.GetEventsDebounce()
+	watcher.reconciliationInterval = config.GetReconciliationInterval()
+
+	if watcher.store == nil || watcher.store.GetPath() != config.GetStateFile() {
+		watcher.store = state.NewStore(config.GetStateFile())
 	}
+
+	watcher.updateMutex.Lock()
+	defer watcher.updateMutex.Unlock()
+
+	// The resolvers are built again with the reloaded address sources, certificate authorities and client certificate.
+	watcher.resolvers = make(map[string]*address.Resolver)
+}
+
+// GetInterval returns the interval at which the watcher checks for changes.
+func (watcher *Watcher) GetInterval() time.Duration {
+	return watcher.interval
+}
+
+// SetDryRun enables or disables the dry-run mode, in which the changes are only reported without being made
+// and the state is left untouched.
+func (watcher *Watcher) SetDryRun(dryRun bool) {
+	watcher.dryRun = dryRun
 }
 
 // Start starts the watcher.
@@ -45,6 +122,21 @@ func (watcher *Watcher) Start() error {
 		return nil
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
+	providers, err := registry.NewFromConfiguration(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	watcher.start(ctx, cancel, providers)
+
+	return nil
+}
+
+// start starts the watcher with the given provider instances, which are released when the context is cancelled.
+func (watcher *Watcher) start(ctx context.Context, cancel context.CancelFunc, providers *registry.Registry) {
 	log.DebugWithFields(
 		"watcher is starting",
 		log.FieldsMap{
@@ -52,20 +144,41 @@ func (watcher *Watcher) Start() error {
 		},
 	)
 
-	var err error
+	watcher.configure()
 
-	watcher.client, err = api.NewClient()
-	if err != nil {
-		return err
+	watcher.cancel = cancel
+	watcher.providers = providers
+	logIgnoredAttributes(providers, configuration.GetConfiguration().GetRecords())
+
+	if err := watcher.store.Load(); err != nil {
+		log.WarnfWithFields(
+			"failed to load state from `%s`, starting with an empty state: %s",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+			watcher.store.GetPath(),
+			err.Error(),
+		)
 	}
 
 	watcher.ticker = time.NewTicker(watcher.interval)
 	watcher.stopChannel = make(chan struct{})
+	watcher.monitor = watcher.startMonitor()
 	watcher.running = true
 
 	watcher.waitGroup.Add(1)
 	go func() {
 		defer watcher.waitGroup.Done()
+
+		var events <-chan struct{}
+		if watcher.monitor != nil {
+			events = watcher.monitor.GetEvents()
+		}
+
+		debounceTimer := time.NewTimer(watcher.debounce)
+		debounceTimer.Stop()
+		defer debounceTimer.Stop()
+
 		for {
 			select {
 			case <-watcher.ticker.C:
@@ -75,7 +188,38 @@ func (watcher *Watcher) Start() error {
 						"source": "watcher",
 					},
 				)
-				watcher.updateDomainRecords()
+				watcher.updateDomainRecords(ctx)
+			case <-events:
+				log.DebugfWithFields(
+					"local address change detected, updating domain records in %s",
+					log.FieldsMap{
+						"source": "watcher",
+					},
+					watcher.debounce.String(),
+				)
+				if !debounceTimer.Stop() {
+					select {
+					case <-debounceTimer.C:
+					default:
+					}
+				}
+				debounceTimer.Reset(watcher.debounce)
+			case <-debounceTimer.C:
+				log.DebugWithFields(
+					"updating domain records after local address change",
+					log.FieldsMap{
+						"source": "watcher",
+					},
+				)
+				watcher.updateDomainRecords(ctx)
+			case <-watcher.syncChannel:
+				log.DebugWithFields(
+					"updating domain records on request",
+					log.FieldsMap{
+						"source": "watcher",
+					},
+				)
+				watcher.updateDomainRecords(ctx)
 			case <-watcher.stopChannel:
 				log.DebugWithFields(
 					"watcher stop has been requested",
@@ -94,12 +238,15 @@ func (watcher *Watcher) Start() error {
 			"source": "watcher",
 		},
 	)
-	go watcher.updateDomainRecords()
 
-	return nil
+	watcher.waitGroup.Add(1)
+	go func() {
+		defer watcher.waitGroup.Done()
+		watcher.updateDomainRecords(ctx)
+	}()
 }
 
-// Stop stops the watcher.
+// Stop stops the watcher, the in-flight update is aborted.
 func (watcher *Watcher) Stop() {
 	if !watcher.isRunning() {
 		log.DebugWithFields(
@@ -111,14 +258,57 @@ func (watcher *Watcher) Stop() {
 		return
 	}
 
-	watcher.ticker.Stop()
-	close(watcher.stopChannel)
-	watcher.waitGroup.Wait()
-	watcher.running = false
-	watcher.client = nil
+	watcher.cancel()
+	watcher.stop()
 }
 
-// Restart restarts the watcher.
+// Shutdown stops the watcher after the in-flight update has finished.
+// The in-flight update is aborted and the error of the context is returned once the context is done.
+func (watcher *Watcher) Shutdown(ctx context.Context) error {
+	if !watcher.isRunning() {
+		log.DebugWithFields(
+			"watcher is not running",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+		)
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		watcher.stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		watcher.cancel()
+		return nil
+	case <-ctx.Done():
+		log.WarnWithFields(
+			"in-flight update did not finish in time, aborting it",
+			log.FieldsMap{
+				"source": "watcher",
+			},
+		)
+		watcher.cancel()
+		<-stopped
+		return ctx.Err()
+	}
+}
+
+// TriggerSync requests an immediate update of the domain records, the request is ignored if one is already pending.
+func (watcher *Watcher) TriggerSync() {
+	select {
+	case watcher.syncChannel <- struct{}{}:
+	default:
+	}
+}
+
+// Restart restarts the watcher with the current configuration.
+// The provider instances are created before the watcher is stopped, so the watcher keeps running
+// with the previous configuration when they can not be created.
 func (watcher *Watcher) Restart() error {
 	log.DebugWithFields(
 		"restarting watcher",
@@ -126,101 +316,81 @@ func (watcher *Watcher) Restart() error {
 			"source": "watcher",
 		},
 	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	providers, err := registry.NewFromConfiguration(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
 	watcher.Stop()
-	return watcher.Start()
-}
+	watcher.start(ctx, cancel, providers)
 
-// isRunning returns a flag that indicates if the watcher is running.
-func (watcher *Watcher) isRunning() bool {
-	return watcher.running
+	return nil
 }
 
-// updateDomainRecords updates the domain records.
-func (watcher *Watcher) updateDomainRecords() {
-	address, err := helpers.GetExternalAddress()
+// startMonitor starts the monitor of local address changes if it is enabled and supported.
+func (watcher *Watcher) startMonitor() addressMonitor {
+	if !configuration.GetConfiguration().GetWatcher().GetEvents() {
+		return nil
+	}
 
+	monitor, err := watcher.newMonitor()
 	if err != nil {
-		log.ErrorfWithFields(
-			"failed to get external address: %s",
+		log.DebugfWithFields(
+			"local address changes will not be monitored: %s",
 			log.FieldsMap{
-				"source": "helpers",
+				"source": "watcher",
 			},
 			err.Error(),
 		)
-		return
+		return nil
 	}
 
-	monitoredRecords := configuration.GetConfiguration().GetRecords()
+	return monitor
+}
 
-	zones, err := watcher.client.ListZones()
+// newAddressMonitor returns the monitor of local address changes of the system.
+func newAddressMonitor() (addressMonitor, error) {
+	monitor, err := address.NewMonitor()
 	if err != nil {
-		log.ErrorfWithFields(
-			"failed to list zones: %s",
+		return nil, err
+	}
+	return monitor, nil
+}
+
+// stopMonitor stops the monitor of local address changes.
+func (watcher *Watcher) stopMonitor() {
+	if watcher.monitor == nil {
+		return
+	}
+
+	if err := watcher.monitor.Close(); err != nil {
+		log.DebugfWithFields(
+			"failed to stop the local address monitor: %s",
 			log.FieldsMap{
-				"source": "api",
+				"source": "watcher",
 			},
 			err.Error(),
 		)
-		return
 	}
 
-	for _, zone := range zones.Result {
-		zoneRecords, err := watcher.client.ListRecords(zone)
-		if err != nil {
-			log.ErrorfWithFields(
-				"failed to list records for zone `%s`: %s",
-				log.FieldsMap{
-					"zone":   zone.ID,
-					"source": "api",
-				},
-				zone.Name,
-				err.Error(),
-			)
-			continue
-		}
+	watcher.monitor = nil
+}
 
-		for _, zoneRecord := range zoneRecords.Result {
-			for _, monitoredRecord := range monitoredRecords {
-				if zoneRecord.Type == monitoredRecord.Type && zoneRecord.Name == monitoredRecord.Name {
-					if zoneRecord.Content != address {
-						zoneRecord.Content = address
-						_, err := watcher.client.UpdateRecord(zone, zoneRecord)
-						if err != nil {
-							log.ErrorfWithFields(
-								"failed to update record `%s`: %s",
-								log.FieldsMap{
-									"zone":   zone.ID,
-									"record": zoneRecord.ID,
-									"source": "api",
-								},
-								zoneRecord.Name,
-								err.Error(),
-							)
-							return
-						}
-						log.InfofWithFields(
-							"updated record `%s` to `%s`",
-							log.FieldsMap{
-								"zone":   zone.ID,
-								"record": zoneRecord.ID,
-								"source": "watcher",
-							},
-							zoneRecord.Name,
-							zoneRecord.Content,
-						)
-					} else {
-						log.InfofWithFields(
-							"record `%s` is already up to date",
-							log.FieldsMap{
-								"zone":   zone.ID,
-								"record": zoneRecord.ID,
-								"source": "watcher",
-							},
-							zoneRecord.Name,
-						)
-					}
-				}
-			}
-		}
-	}
+// stop stops the ticker and the monitor and waits for the watcher to finish.
+func (watcher *Watcher) stop() {
+	watcher.ticker.Stop()
+	close(watcher.stopChannel)
+	watcher.waitGroup.Wait()
+	watcher.stopMonitor()
+	watcher.running = false
+	watcher.providers = nil
+}
+
+// isRunning returns a flag that indicates if the watcher is running.
+func (watcher *Watcher) isRunning() bool {
+	return watcher.running
 }
@@ -0,0 +1,744 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"github.com/darki73/goflaresync/pkg/address"
+	"github.com/darki73/goflaresync/pkg/address/addresstest"
+	"github.com/darki73/goflaresync/pkg/api"
+	"github.com/darki73/goflaresync/pkg/api/apitest"
+	"github.com/darki73/goflaresync/pkg/api/entities"
+	"github.com/darki73/goflaresync/pkg/configuration"
+	"github.com/darki73/goflaresync/pkg/configuration/cloudflare"
+	providerConfiguration "github.com/darki73/goflaresync/pkg/configuration/provider"
+	"github.com/darki73/goflaresync/pkg/configuration/records"
+	"github.com/darki73/goflaresync/pkg/configuration/rfc2136"
+	"github.com/darki73/goflaresync/pkg/log"
+	"github.com/darki73/goflaresync/pkg/provider"
+	"github.com/darki73/goflaresync/pkg/provider/registry"
+	"github.com/darki73/goflaresync/pkg/provider/rfc2136/rfc2136test"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	log.SetOutput(io.Discard)
+}
+
+// scenario is the definition of a synchronization scenario run against the fake Cloudflare API.
+type scenario struct {
+	server  *apitest.Server
+	ipv4    *addresstest.Source
+	ipv6    *addresstest.Source
+	watcher *Watcher
+}
+
+func newScenario(t *testing.T, monitoredRecords ...*records.Configuration) *scenario {
+	server := apitest.NewServer()
+	t.Cleanup(server.Close)
+
+	config := configuration.InitializeWithDefaults()
+	config.Credentials.Token = apitest.Token
+	config.Records = monitoredRecords
+	config.Watcher.StateFile = ""
+	config.API.BaseURL = server.GetURL()
+	config.API.RateLimit = 0
+	config.API.RetryMinBackoff = time.Millisecond
+	config.API.RetryMaxBackoff = 10 * time.Millisecond
+	configuration.SetConfiguration(config)
+
+	test := &scenario{
+		server: server,
+		ipv4:   addresstest.NewSource("ipv4", "93.184.216.34"),
+		ipv6:   addresstest.NewSource("ipv6", "2606:4700::1"),
+	}
+
+	test.watcher = New()
+	test.watcher.newResolver = func(network string) (*address.Resolver, error) {
+		if network == address.NetworkIPv6 {
+			return addresstest.NewResolver(test.ipv6), nil
+		}
+		return addresstest.NewResolver(test.ipv4), nil
+	}
+
+	return test
+}
+
+func (test *scenario) sync(t *testing.T) {
+	if test.watcher.providers == nil {
+		providers, err := registry.NewFromConfiguration(context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error but got %v", err)
+		}
+		test.watcher.providers = providers
+	}
+
+	test.watcher.updateDomainRecords(context.Background())
+}
+
+func getRecord(t *testing.T, server *apitest.Server, zoneName string, name string, recordType string) *entities.Record {
+	for _, record := range server.GetRecords(zoneName) {
+		if record.Name == name && record.Type == recordType {
+			return record
+		}
+	}
+
+	t.Fatalf("Expected record `%s` of type `%s` to exist in zone `%s`", name, recordType, zoneName)
+	return nil
+}
+
+func TestSyncUpdatesRecordsOfBothFamilies(t *testing.T) {
+	test := newScenario(t,
+		&records.Configuration{Type: "A", Name: "home.example.com"},
+		&records.Configuration{Type: "AAAA", Name: "home.example.com"},
+	)
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1", TTL: 300, Comment: "keep"})
+	test.server.AddRecord("example.com", &entities.Record{Type: "AAAA", Name: "home.example.com", Content: "2001:db8::ffff", TTL: 1})
+
+	test.sync(t)
+
+	ipv4 := getRecord(t, test.server, "example.com", "home.example.com", "A")
+	if ipv4.Content != "93.184.216.34" {
+		t.Errorf("Expected '93.184.216.34' but got '%s'", ipv4.Content)
+	}
+
+	if ipv4.TTL != 300 || ipv4.Comment != "keep" {
+		t.Errorf("Expected unmanaged attributes to be preserved but got %+v", ipv4)
+	}
+
+	if ipv6 := getRecord(t, test.server, "example.com", "home.example.com", "AAAA"); ipv6.Content != "2606:4700::1" {
+		t.Errorf("Expected '2606:4700::1' but got '%s'", ipv6.Content)
+	}
+}
+
+func TestSyncResolvesOnlyFamiliesOfMonitoredRecords(t *testing.T) {
+	test := newScenario(t, &records.Configuration{Type: "AAAA", Name: "home.example.com"})
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "AAAA", Name: "home.example.com", Content: "2001:db8::ffff"})
+	test.ipv4.SetError(errors.New("unreachable"))
+
+	test.sync(t)
+
+	if ipv6 := getRecord(t, test.server, "example.com", "home.example.com", "AAAA"); ipv6.Content != "2606:4700::1" {
+		t.Errorf("Expected '2606:4700::1' but got '%s'", ipv6.Content)
+	}
+
+	if calls := test.ipv4.GetCalls(); calls != 0 {
+		t.Errorf("Expected the IPv4 address not to be resolved but it was resolved %d times", calls)
+	}
+}
+
+func TestSyncKeepsRecordsOfFamilyWithoutAddress(t *testing.T) {
+	test := newScenario(t,
+		&records.Configuration{Type: "A", Name: "home.example.com"},
+		&records.Configuration{Type: "AAAA", Name: "home.example.com"},
+	)
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+	test.server.AddRecord("example.com", &entities.Record{Type: "AAAA", Name: "home.example.com", Content: "2001:db8::ffff"})
+	test.ipv6.SetError(errors.New("unreachable"))
+
+	test.sync(t)
+
+	if ipv4 := getRecord(t, test.server, "example.com", "home.example.com", "A"); ipv4.Content != "93.184.216.34" {
+		t.Errorf("Expected '93.184.216.34' but got '%s'", ipv4.Content)
+	}
+
+	if ipv6 := getRecord(t, test.server, "example.com", "home.example.com", "AAAA"); ipv6.Content != "2001:db8::ffff" {
+		t.Errorf("Expected '2001:db8::ffff' but got '%s'", ipv6.Content)
+	}
+}
+
+func TestSyncReusesResolversUntilConfiguredAgain(t *testing.T) {
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com"})
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+
+	builds := 0
+	newResolver := test.watcher.newResolver
+	test.watcher.newResolver = func(network string) (*address.Resolver, error) {
+		builds++
+		return newResolver(network)
+	}
+
+	test.sync(t)
+	test.sync(t)
+
+	if builds != 1 {
+		t.Errorf("Expected the resolver to be built once but it was built %d times", builds)
+	}
+
+	test.watcher.configure()
+	test.sync(t)
+
+	if builds != 2 {
+		t.Errorf("Expected the resolver to be built again after the configuration was applied but it was built %d times", builds)
+	}
+}
+
+func TestSyncSkipsAPIWhenAddressIsUnchanged(t *testing.T) {
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com"})
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+
+	test.sync(t)
+	test.server.ResetRequests()
+
+	test.sync(t)
+
+	if count := test.server.CountRequests(""); count != 0 {
+		t.Errorf("Expected no API calls but got %d", count)
+	}
+}
+
+func TestSyncUpdatesKnownRecordsWithoutLookup(t *testing.T) {
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com"})
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+
+	test.sync(t)
+	test.server.ResetRequests()
+
+	test.ipv4.SetAddress("93.184.216.35")
+	test.sync(t)
+
+	if record := getRecord(t, test.server, "example.com", "home.example.com", "A"); record.Content != "93.184.216.35" {
+		t.Errorf("Expected '93.184.216.35' but got '%s'", record.Content)
+	}
+
+	if count := test.server.CountRequests(http.MethodGet); count != 0 {
+		t.Errorf("Expected no lookups but got %d", count)
+	}
+
+	if count := test.server.CountRequests(http.MethodPut); count != 1 {
+		t.Errorf("Expected 1 update but got %d", count)
+	}
+}
+
+func TestSyncCreatesMissingRecordInLongestZone(t *testing.T) {
+	proxied := true
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "api.dev.example.com", CreateIfMissing: true, Proxied: &proxied, TTL: 120})
+	test.server.AddZone("example.com")
+	test.server.AddZone("dev.example.com")
+
+	test.sync(t)
+
+	if records := test.server.GetRecords("example.com"); len(records) != 0 {
+		t.Errorf("Expected no records in `example.com` but got %d", len(records))
+	}
+
+	record := getRecord(t, test.server, "dev.example.com", "api.dev.example.com", "A")
+	if record.Content != "93.184.216.34" || !record.Proxied || record.TTL != records.DefaultTTL {
+		t.Errorf("Expected the record to be created proxied with the automatic TTL but got %+v", record)
+	}
+}
+
+func TestSyncDoesNotUpdateTTLOfProxiedRecord(t *testing.T) {
+	proxied := true
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com", Proxied: &proxied, TTL: 300})
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "93.184.216.34", Proxied: true, TTL: 1})
+
+	test.sync(t)
+
+	if count := test.server.CountRequests(http.MethodPut); count != 0 {
+		t.Errorf("Expected no updates but got %d", count)
+	}
+
+	test.server.ResetRequests()
+	test.sync(t)
+
+	if count := test.server.CountRequests(""); count != 0 {
+		t.Errorf("Expected the record to be up to date without API calls but got %d", count)
+	}
+}
+
+func TestSyncRetriesTransientFailures(t *testing.T) {
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com"})
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+	test.server.Fail(&apitest.Failure{Method: http.MethodPut, StatusCode: http.StatusServiceUnavailable, Times: 2})
+
+	test.sync(t)
+
+	if record := getRecord(t, test.server, "example.com", "home.example.com", "A"); record.Content != "93.184.216.34" {
+		t.Errorf("Expected '93.184.216.34' but got '%s'", record.Content)
+	}
+
+	if count := test.server.CountRequests(http.MethodPut); count != 3 {
+		t.Errorf("Expected 3 update attempts but got %d", count)
+	}
+}
+
+func TestSyncStopsOnRejectedCredentials(t *testing.T) {
+	test := newScenario(t,
+		&records.Configuration{Type: "A", Name: "one.example.com"},
+		&records.Configuration{Type: "A", Name: "two.example.com"},
+	)
+	test.server.AddZone("example.com")
+	test.sync(t)
+
+	test.server.ResetRequests()
+	test.server.Fail(&apitest.Failure{
+		StatusCode: http.StatusForbidden,
+		Errors:     []*entities.Error{{Code: 10000, Message: "Authentication error"}},
+		Times:      10,
+	})
+	test.watcher.store.SetLastReconciliation(time.Time{})
+
+	test.sync(t)
+
+	if count := test.server.CountRequests(""); count != 1 {
+		t.Errorf("Expected the synchronization to stop after the first rejected request but got %d requests", count)
+	}
+
+	if lastError := test.watcher.store.GetLastError(); !strings.HasPrefix(lastError, "record `one.example.com` of type `A`") {
+		t.Errorf("Expected the failure to be remembered but got '%s'", lastError)
+	}
+
+	if !test.watcher.store.GetLastAttempt().After(test.watcher.store.GetLastSync()) {
+		t.Errorf("Expected the last attempt to be after the last successful synchronization")
+	}
+}
+
+func TestSyncFailsOnlyRecordOfForbiddenZone(t *testing.T) {
+	test := newScenario(t,
+		&records.Configuration{Type: "A", Name: "home.example.com"},
+		&records.Configuration{Type: "A", Name: "home.example.org"},
+	)
+	forbidden := test.server.AddZone("example.com")
+	test.server.AddZone("example.org")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+	test.server.AddRecord("example.org", &entities.Record{Type: "A", Name: "home.example.org", Content: "192.0.2.1"})
+	test.server.Fail(&apitest.Failure{
+		Path:       "/zones/" + forbidden.ID + "/dns_records",
+		StatusCode: http.StatusForbidden,
+		Times:      10,
+	})
+
+	results, err := test.watcher.Sync(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	expected := []Status{StatusFailed, StatusUpdated}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results but got %d", len(expected), len(results))
+	}
+
+	for index, result := range results {
+		if result.GetStatus() != expected[index] {
+			t.Errorf("Expected '%s' for record `%s` but got '%s'", expected[index], result.GetRecord().GetName(), result.GetStatus())
+		}
+	}
+
+	if errors.Is(results[0].GetError(), api.ErrInvalidCredentials) {
+		t.Errorf("Expected the forbidden request not to be reported as '%v'", api.ErrInvalidCredentials)
+	}
+
+	if record := getRecord(t, test.server, "example.org", "home.example.org", "A"); record.Content != "93.184.216.34" {
+		t.Errorf("Expected '93.184.216.34' but got '%s'", record.Content)
+	}
+}
+
+func TestSyncRefusesPrivateAddress(t *testing.T) {
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com"})
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+	test.ipv4.SetAddress("10.0.0.1")
+
+	test.sync(t)
+
+	if record := getRecord(t, test.server, "example.com", "home.example.com", "A"); record.Content != "192.0.2.1" {
+		t.Errorf("Expected '192.0.2.1' but got '%s'", record.Content)
+	}
+}
+
+func TestSyncWithoutAddress(t *testing.T) {
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com"})
+	test.server.AddZone("example.com")
+	test.ipv4.SetError(errors.New("connection refused"))
+
+	test.sync(t)
+
+	if count := test.server.CountRequests(http.MethodGet); count != 1 {
+		t.Errorf("Expected only the authentication request but got %d requests", count)
+	}
+}
+
+func TestSyncUsesProviderOfEveryRecord(t *testing.T) {
+	test := newScenario(t,
+		&records.Configuration{Type: "A", Name: "home.example.com"},
+		&records.Configuration{Type: "A", Name: "home.example.org", Provider: "secondary"},
+	)
+	test.server.AddZone("example.com")
+	test.server.AddZone("example.org")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+	test.server.AddRecord("example.org", &entities.Record{Type: "A", Name: "home.example.org", Content: "192.0.2.1"})
+
+	secondary := providerConfiguration.New("secondary", providerConfiguration.TypeCloudflare)
+	secondary.Credentials = &cloudflare.Configuration{Email: apitest.Email, Key: apitest.Key}
+	configuration.GetConfiguration().Providers = []*providerConfiguration.Configuration{secondary}
+
+	test.sync(t)
+
+	if names := test.watcher.providers.GetNames(); len(names) != 2 {
+		t.Fatalf("Expected 2 providers but got %v", names)
+	}
+
+	for _, zoneName := range []string{"example.com", "example.org"} {
+		if record := getRecord(t, test.server, zoneName, "home."+zoneName, "A"); record.Content != "93.184.216.34" {
+			t.Errorf("Expected '93.184.216.34' but got '%s'", record.Content)
+		}
+	}
+
+	if knownRecords := test.watcher.store.GetRecords("secondary", "A", "home.example.org"); len(knownRecords) != 1 {
+		t.Errorf("Expected the record to be remembered for provider 'secondary' but got %d records", len(knownRecords))
+	}
+
+	if knownRecords := test.watcher.store.GetRecords(providerConfiguration.DefaultName, "A", "home.example.org"); len(knownRecords) != 0 {
+		t.Errorf("Expected the record not to be remembered for provider '%s' but got %d records", providerConfiguration.DefaultName, len(knownRecords))
+	}
+}
+
+func TestSyncUpdatesRecordsWithRFC2136Provider(t *testing.T) {
+	test := newScenario(t,
+		&records.Configuration{Type: "A", Name: "home.example.net", Provider: "internal"},
+		&records.Configuration{Type: "AAAA", Name: "new.example.net", Provider: "internal", CreateIfMissing: true},
+	)
+
+	server := rfc2136test.NewServer()
+	t.Cleanup(server.Close)
+	server.AddZone("example.net")
+	server.AddRecord("example.net", "home.example.net. 600 IN A 192.0.2.1")
+
+	internal := providerConfiguration.New("internal", providerConfiguration.TypeRFC2136)
+	internal.RFC2136 = &rfc2136.Configuration{
+		Server:        server.GetAddress(),
+		TSIGKeyName:   rfc2136test.KeyName,
+		TSIGSecret:    rfc2136test.Secret,
+		TSIGAlgorithm: rfc2136test.Algorithm,
+	}
+	configuration.GetConfiguration().Providers = []*providerConfiguration.Configuration{internal}
+
+	test.sync(t)
+
+	if contents := server.GetContents("home.example.net", "A"); len(contents) != 1 || contents[0] != "93.184.216.34" {
+		t.Errorf("Expected only '93.184.216.34' but got %v", contents)
+	}
+
+	if contents := server.GetContents("new.example.net", "AAAA"); len(contents) != 1 || contents[0] != "2606:4700::1" {
+		t.Errorf("Expected only '2606:4700::1' but got %v", contents)
+	}
+
+	if count := test.server.CountRequests(""); count != 0 {
+		t.Errorf("Expected no Cloudflare API calls but got %d", count)
+	}
+
+	test.ipv4.SetAddress("93.184.216.35")
+	test.sync(t)
+
+	if contents := server.GetContents("home.example.net", "A"); len(contents) != 1 || contents[0] != "93.184.216.35" {
+		t.Errorf("Expected only '93.184.216.35' but got %v", contents)
+	}
+}
+
+func TestSyncIgnoresAttributesUnsupportedByProvider(t *testing.T) {
+	proxied := true
+	comment := "Managed by GoFlareSync"
+	test := newScenario(t,
+		&records.Configuration{Type: "A", Name: "home.example.net", Provider: "internal", Proxied: &proxied, TTL: 600, Comment: &comment, Tags: []string{"owner:ops"}},
+	)
+
+	server := rfc2136test.NewServer()
+	t.Cleanup(server.Close)
+	server.AddZone("example.net")
+	server.AddRecord("example.net", "home.example.net. 600 IN A 93.184.216.34")
+
+	internal := providerConfiguration.New("internal", providerConfiguration.TypeRFC2136)
+	internal.RFC2136 = &rfc2136.Configuration{
+		Server:        server.GetAddress(),
+		TSIGKeyName:   rfc2136test.KeyName,
+		TSIGSecret:    rfc2136test.Secret,
+		TSIGAlgorithm: rfc2136test.Algorithm,
+	}
+	configuration.GetConfiguration().Providers = []*providerConfiguration.Configuration{internal}
+
+	test.sync(t)
+
+	recordProvider, err := test.watcher.providers.Get("internal")
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if ignoredAttributes := getIgnoredAttributes(recordProvider, configuration.GetConfiguration().GetRecords()[0]); strings.Join(ignoredAttributes, ",") != "proxied,comment,tags" {
+		t.Errorf("Expected 'proxied,comment,tags' to be ignored but got %v", ignoredAttributes)
+	}
+
+	if count := server.CountUpdates(); count != 0 {
+		t.Errorf("Expected no updates but got %d", count)
+	}
+
+	queries := server.CountQueries()
+	test.sync(t)
+
+	if count := server.CountQueries(); count != queries {
+		t.Errorf("Expected the record to be up to date without queries but got %d", count-queries)
+	}
+}
+
+func TestSyncReportsOutcomeOfEveryRecord(t *testing.T) {
+	test := newScenario(t,
+		&records.Configuration{Type: "A", Name: "home.example.com"},
+		&records.Configuration{Type: "A", Name: "same.example.com"},
+		&records.Configuration{Type: "A", Name: "new.example.com", CreateIfMissing: true},
+		&records.Configuration{Type: "A", Name: "missing.example.com"},
+		&records.Configuration{Type: "A", Name: "home.example.org"},
+		&records.Configuration{Type: "AAAA", Name: "home.example.com"},
+	)
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "same.example.com", Content: "93.184.216.34"})
+	test.ipv6.SetError(errors.New("network is unreachable"))
+
+	results, err := test.watcher.Sync(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	expected := []Status{StatusUpdated, StatusUnchanged, StatusCreated, StatusNotFound, StatusNotFound, StatusSkipped}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results but got %d", len(expected), len(results))
+	}
+
+	for index, result := range results {
+		if result.GetStatus() != expected[index] {
+			t.Errorf("Expected '%s' for record `%s` but got '%s'", expected[index], result.GetRecord().GetName(), result.GetStatus())
+		}
+	}
+
+	if !errors.Is(results[5].GetError(), errNoAddress) {
+		t.Errorf("Expected '%v' but got '%v'", errNoAddress, results[5].GetError())
+	}
+}
+
+func TestSyncOfSelectedRecordsKeepsOtherKnownRecords(t *testing.T) {
+	monitoredRecords := []*records.Configuration{
+		{Type: "A", Name: "one.example.com"},
+		{Type: "A", Name: "two.example.com"},
+	}
+	test := newScenario(t, monitoredRecords...)
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "one.example.com", Content: "192.0.2.1"})
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "two.example.com", Content: "192.0.2.1"})
+
+	test.sync(t)
+	lastReconciliation := test.watcher.store.GetLastReconciliation()
+
+	test.ipv4.SetAddress("93.184.216.35")
+	test.watcher.store.SetLastReconciliation(time.Time{})
+
+	results, err := test.watcher.Sync(context.Background(), monitoredRecords[:1])
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if len(results) != 1 || results[0].GetStatus() != StatusUpdated {
+		t.Fatalf("Expected only the selected record to be updated but got %+v", results)
+	}
+
+	if record := getRecord(t, test.server, "example.com", "two.example.com", "A"); record.Content != "93.184.216.34" {
+		t.Errorf("Expected '93.184.216.34' but got '%s'", record.Content)
+	}
+
+	if knownRecords := test.watcher.store.GetRecords(providerConfiguration.DefaultName, "A", "two.example.com"); len(knownRecords) != 1 {
+		t.Errorf("Expected the other record to be remembered but got %d records", len(knownRecords))
+	}
+
+	if !test.watcher.store.GetLastReconciliation().IsZero() || lastReconciliation.IsZero() {
+		t.Errorf("Expected the synchronization of selected records not to count as a reconciliation")
+	}
+}
+
+func TestSyncInDryRunModeReportsChangesWithoutMakingThem(t *testing.T) {
+	test := newScenario(t,
+		&records.Configuration{Type: "A", Name: "home.example.com", TTL: 120},
+		&records.Configuration{Type: "A", Name: "new.example.com", CreateIfMissing: true},
+	)
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1", TTL: 300})
+	test.watcher.SetDryRun(true)
+
+	results, err := test.watcher.Sync(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if len(results) != 2 || results[0].GetStatus() != StatusUpdated || results[1].GetStatus() != StatusCreated {
+		t.Fatalf("Expected the record to be updated and created but got %+v", results)
+	}
+
+	if count := test.server.CountRequests(http.MethodPut) + test.server.CountRequests(http.MethodPost); count != 0 {
+		t.Errorf("Expected no changes but got %d", count)
+	}
+
+	if record := getRecord(t, test.server, "example.com", "home.example.com", "A"); record.Content != "192.0.2.1" || record.TTL != 300 {
+		t.Errorf("Expected the record to be left untouched but got %+v", record)
+	}
+
+	expected := []*AttributeChange{
+		{Name: "content", Old: "192.0.2.1", New: "93.184.216.34"},
+		{Name: "ttl", Old: "300", New: "120"},
+	}
+	changes := results[0].GetChanges()
+	if len(changes) != 1 || changes[0].Action != ActionUpdate || changes[0].Zone != "example.com" || len(changes[0].Attributes) != len(expected) {
+		t.Fatalf("Expected a single update of %d attributes but got %+v", len(expected), changes)
+	}
+
+	for index, attribute := range changes[0].Attributes {
+		if *attribute != *expected[index] {
+			t.Errorf("Expected '%+v' but got '%+v'", *expected[index], *attribute)
+		}
+	}
+
+	if changes := results[1].GetChanges(); len(changes) != 1 || changes[0].Action != ActionCreate || changes[0].Attributes[0].New != "93.184.216.34" {
+		t.Errorf("Expected the record to be created with the address but got %+v", changes)
+	}
+
+	if knownRecords := test.watcher.store.GetRecords(providerConfiguration.DefaultName, "A", "home.example.com"); len(knownRecords) != 0 {
+		t.Errorf("Expected the state to be left untouched but got %d records", len(knownRecords))
+	}
+}
+
+func TestTriggerSyncUpdatesRecordsImmediately(t *testing.T) {
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com"})
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+	configuration.GetConfiguration().Watcher.Events = false
+	configuration.GetConfiguration().Watcher.Interval = time.Hour
+
+	if err := test.watcher.Start(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	waitForContent := func(expected string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if getRecord(t, test.server, "example.com", "home.example.com", "A").Content == expected {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("Expected '%s' but got '%s'", expected, getRecord(t, test.server, "example.com", "home.example.com", "A").Content)
+	}
+
+	waitForContent("93.184.216.34")
+
+	test.ipv4.SetAddress("93.184.216.35")
+	test.watcher.TriggerSync()
+	waitForContent("93.184.216.35")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := test.watcher.Shutdown(ctx); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+
+	if test.watcher.isRunning() {
+		t.Errorf("Expected the watcher to be stopped")
+	}
+}
+
+// fakeMonitor is the monitor which reports the local address changes sent by the test.
+type fakeMonitor struct {
+	events chan struct{}
+}
+
+func (monitor *fakeMonitor) GetEvents() <-chan struct{} {
+	return monitor.events
+}
+
+func (monitor *fakeMonitor) Close() error {
+	return nil
+}
+
+func TestBurstOfAddressChangesCausesSingleUpdateAfterDebounce(t *testing.T) {
+	test := newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com"})
+	test.server.AddZone("example.com")
+	test.server.AddRecord("example.com", &entities.Record{Type: "A", Name: "home.example.com", Content: "192.0.2.1"})
+	debounce := 300 * time.Millisecond
+	configuration.GetConfiguration().Watcher.Events = true
+	configuration.GetConfiguration().Watcher.EventsDebounce = debounce
+	configuration.GetConfiguration().Watcher.Interval = time.Hour
+
+	monitor := &fakeMonitor{events: make(chan struct{})}
+	test.watcher.newMonitor = func() (addressMonitor, error) {
+		return monitor, nil
+	}
+
+	if err := test.watcher.Start(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	defer test.watcher.Stop()
+
+	waitForCalls := func(expected int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if test.ipv4.GetCalls() >= expected {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("Expected %d updates but got %d", expected, test.ipv4.GetCalls())
+	}
+
+	waitForCalls(1)
+
+	test.ipv4.SetAddress("93.184.216.35")
+	for i := 0; i < 5; i++ {
+		monitor.events <- struct{}{}
+		time.Sleep(debounce / 10)
+	}
+	lastEvent := time.Now()
+
+	if calls := test.ipv4.GetCalls(); calls != 1 {
+		t.Errorf("Expected no update during the burst but got %d", calls-1)
+	}
+
+	waitForCalls(2)
+	if elapsed := time.Since(lastEvent); elapsed < debounce-debounce/10 {
+		t.Errorf("Expected the update %s after the last change but it ran after %s", debounce, elapsed)
+	}
+
+	time.Sleep(2 * debounce)
+
+	if calls := test.ipv4.GetCalls(); calls != 2 {
+		t.Errorf("Expected a single update after the burst but got %d", calls-1)
+	}
+
+	if content := getRecord(t, test.server, "example.com", "home.example.com", "A").Content; content != "93.184.216.35" {
+		t.Errorf("Expected '93.184.216.35' but got '%s'", content)
+	}
+}
+
+func TestSyncWithUnknownProvider(t *testing.T) {
+	newScenario(t, &records.Configuration{Type: "A", Name: "home.example.com", Provider: "missing"})
+
+	if _, err := registry.NewFromConfiguration(context.Background()); !errors.Is(err, provider.ErrUnknownProvider) {
+		t.Errorf("Expected '%v' but got '%v'", provider.ErrUnknownProvider, err)
+	}
+}
+
+func TestNewClientRejectsInvalidToken(t *testing.T) {
+	newScenario(t)
+	configuration.GetConfiguration().Credentials.Token = "invalid"
+
+	if _, err := api.NewClient(); !errors.Is(err, api.ErrInvalidCredentials) {
+		t.Errorf("Expected '%v' but got '%v'", api.ErrInvalidCredentials, err)
+	}
+}